@@ -0,0 +1,161 @@
+package main
+
+import (
+	"cmp"
+	"maps"
+	"slices"
+
+	"github.com/prairiegroupinc/linearsummarybot/yearmonth"
+)
+
+// CarryOverConfig decides what happens to the unused budget of a bucket.
+type CarryOverConfig struct {
+	// Mode is one of CarryNone (default), CarryForward or CarryPoolQuarter.
+	Mode CarryOverMode `json:"mode"`
+
+	// Max is the maximum number of points carried forward in CarryForward mode, 0 for no limit.
+	Max float64 `json:"max"`
+}
+
+type CarryOverMode string
+
+const (
+	// CarryNone forgets unused budget at the end of each month.
+	CarryNone CarryOverMode = "none"
+
+	// CarryForward adds unused budget, up to Max points, to the next month.
+	CarryForward CarryOverMode = "carry"
+
+	// CarryPoolQuarter pools budget within a quarter: both unused budget and
+	// overspending carry over to the following months of the same quarter.
+	CarryPoolQuarter CarryOverMode = "quarter"
+)
+
+// BucketLedger tracks the budget of a bucket month by month.
+type BucketLedger struct {
+	Name    string
+	Mode    CarryOverMode
+	Entries []*LedgerEntry
+}
+
+// Last returns the entry of the last month.
+func (bl *BucketLedger) Last() *LedgerEntry {
+	return bl.Entries[len(bl.Entries)-1]
+}
+
+type LedgerEntry struct {
+	Key       yearmonth.YM
+	Budget    float64 // the month's own budget
+	CarriedIn float64 // balance carried from the previous month, negative if overspent
+	Used      float64
+}
+
+func (le *LedgerEntry) MonthName() string {
+	return le.Key.Time().Format("Jan 2006")
+}
+
+// Available returns the budget that can be spent in the month.
+func (le *LedgerEntry) Available() float64 {
+	return max(le.Budget+le.CarriedIn, 0)
+}
+
+// Balance is unused budget if positive, or overspending if negative.
+func (le *LedgerEntry) Balance() float64 {
+	return le.Budget + le.CarriedIn - le.Used
+}
+
+// IsUnderfunded returns whether the bucket needed more than was available.
+func (le *LedgerEntry) IsUnderfunded() bool {
+	return le.Balance() < 0
+}
+
+// carriedIn returns how much of the previous month's balance carries into month ym.
+// The configured maximum is in points and is scaled by perPoint.
+func (co *CarryOverConfig) carriedIn(prev *LedgerEntry, ym yearmonth.YM, perPoint float64) float64 {
+	if prev == nil || co == nil {
+		return 0
+	}
+	switch co.Mode {
+	case CarryForward:
+		carry := max(prev.Balance(), 0)
+		if co.Max > 0 {
+			carry = min(carry, co.Max*perPoint)
+		}
+		return carry
+	case CarryPoolQuarter:
+		if ym.Quarter() != prev.Key.Quarter() {
+			return 0
+		}
+		return prev.Balance()
+	default:
+		return 0
+	}
+}
+
+// computeBudgetLedgers tracks each budgeted bucket from the start month
+// through the last month of the report, including months without issues, and
+// records the carried budget in the report's initiatives.
+func computeBudgetLedgers(months []*MonthData, start yearmonth.YM, absences []*Absence, conv conversion) []*BucketLedger {
+	if len(months) == 0 {
+		return nil
+	}
+	start = max(start, months[0].Key)
+	end := months[len(months)-1].Key
+
+	byKey := make(map[yearmonth.YM]*MonthData, len(months))
+	for _, md := range months {
+		byKey[md.Key] = md
+	}
+
+	ledgers := make(map[string]*BucketLedger)
+	for ym := start; ym <= end; ym = ym.Next() {
+		md := byKey[ym]
+		var budget map[string]float64
+		if md != nil {
+			budget = md.Budget
+		} else {
+			budget = config.monthBudget(ym, deriveMonthCapacity(ym, absences, conv).Total, conv.perPoint())
+		}
+
+		// include buckets that only have budget carried from earlier months
+		names := make(map[string]bool)
+		for name := range budget {
+			names[name] = true
+		}
+		for name := range ledgers {
+			names[name] = true
+		}
+
+		for name := range names {
+			ledger := ledgers[name]
+			if ledger == nil {
+				ledger = &BucketLedger{Name: name, Mode: CarryNone}
+				if co := config.CarryOver[name]; co != nil && co.Mode != "" {
+					ledger.Mode = co.Mode
+				}
+				ledgers[name] = ledger
+			}
+			var prev *LedgerEntry
+			if n := len(ledger.Entries); n > 0 && ledger.Entries[n-1].Key.Next() == ym {
+				prev = ledger.Entries[n-1]
+			}
+
+			entry := &LedgerEntry{
+				Key:       ym,
+				Budget:    budget[name],
+				CarriedIn: config.CarryOver[name].carriedIn(prev, ym, conv.perPoint()),
+			}
+			if md != nil {
+				if idata := md.Initiatives[name]; idata != nil {
+					entry.Used = idata.Fixed + idata.Planned + idata.Flex
+					idata.CarriedIn = entry.CarriedIn
+				}
+			}
+			ledger.Entries = append(ledger.Entries, entry)
+		}
+	}
+
+	return slices.SortedFunc(maps.Values(ledgers), func(a, b *BucketLedger) int {
+		return cmp.Compare(a.Name, b.Name)
+	})
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/prairiegroupinc/linearsummarybot/yearmonth"
+)
+
+func TestComputeBudgetLedgers(t *testing.T) {
+	saved := config
+	defer func() { config = saved }()
+	config.DefaultCapacity = 100
+	config.DefaultBudget = map[string]BudgetAmount{
+		"Reliability": {Points: 20},
+		"Last Minute": {Points: 10},
+		"Pooled":      {Points: 10},
+	}
+	config.ByMonth = nil
+	config.CarryOver = map[string]*CarryOverConfig{
+		"Reliability": {Mode: CarryForward, Max: 15},
+		"Pooled":      {Mode: CarryPoolQuarter},
+	}
+
+	month := func(ym yearmonth.YM, used map[string]float64) *MonthData {
+		md := &MonthData{
+			Key:         ym,
+			Initiatives: make(map[string]*InitiativeData),
+			Budget:      config.monthBudget(ym, 100, 1),
+		}
+		for name, points := range used {
+			md.LookupInitiative(name).Planned = points
+		}
+		return md
+	}
+	// May is missing from the report, its budget is fully unused
+	months := []*MonthData{
+		month(yearmonth.Make(2025, 4), map[string]float64{"Reliability": 5, "Last Minute": 2, "Pooled": 14}),
+		month(yearmonth.Make(2025, 6), map[string]float64{"Reliability": 20, "Pooled": 0}),
+		month(yearmonth.Make(2025, 7), map[string]float64{"Pooled": 3}),
+	}
+
+	ledgers := computeBudgetLedgers(months, yearmonth.Make(2025, 4), nil, conversion{})
+	if len(ledgers) != 3 {
+		t.Fatalf("got %d ledgers, want 3", len(ledgers))
+	}
+
+	wantBalances := map[string][]float64{
+		"Last Minute": {8, 10, 10, 10},                // nothing carried
+		"Pooled":      {-4, 6, 16, 7},                 // Apr-Jun pooled, July starts a new quarter
+		"Reliability": {15, 15 + 20, 15 + 0, 15 + 20}, // carry capped at 15
+	}
+	for _, ledger := range ledgers {
+		want := wantBalances[ledger.Name]
+		if len(ledger.Entries) != len(want) {
+			t.Errorf("%s: got %d entries, want %d", ledger.Name, len(ledger.Entries), len(want))
+			continue
+		}
+		for i, e := range ledger.Entries {
+			if e.Balance() != want[i] {
+				t.Errorf("%s: %s balance = %v, want %v", ledger.Name, e.MonthName(), e.Balance(), want[i])
+			}
+		}
+	}
+
+	if got := months[1].Initiatives["Reliability"].CarriedIn; got != 15 {
+		t.Errorf("June Reliability CarriedIn = %v, want 15", got)
+	}
+}
@@ -0,0 +1,429 @@
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"log"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/prairiegroupinc/linearsummarybot/yearmonth"
+)
+
+// CalendarConfig derives monthly capacity from the working days, holidays and
+// time off of team members. It applies to months without an explicit capacity.
+type CalendarConfig struct {
+	// WorkWeek lists working weekdays, e.g. ["Mon", "Tue"]; Monday to Friday by default.
+	WorkWeek []string `json:"work_week"`
+
+	// Holidays are dates ("2025-07-04") or inclusive ranges ("2025-12-24..2025-12-26").
+	Holidays []string `json:"holidays"`
+
+	// HolidaysICS is the path of an .ics file whose events are holidays.
+	HolidaysICS string `json:"holidays_ics"`
+
+	// Teams are keyed by Linear team key.
+	Teams map[string]*TeamCalendarConfig `json:"teams"`
+
+	// TimeOffICS lists .ics files or URLs with time off, loaded on every report.
+	TimeOffICS []string `json:"time_off_ics"`
+
+	// TimeOffFilter is a regular expression that event summaries must match
+	// to count as time off; all events count if empty.
+	TimeOffFilter string `json:"time_off_filter"`
+
+	// Aliases map attendee e-mails and names used in the calendar to Linear user names.
+	Aliases map[string]string `json:"aliases"`
+
+	// PointsPerDay is deducted from configured and default capacities for
+	// each working day a person is away. If 0, absences only reduce the
+	// capacity of months derived from Teams.
+	PointsPerDay float64 `json:"points_per_day"`
+
+	workWeek      [7]bool
+	holidays      map[time.Time]bool
+	timeOffFilter *regexp.Regexp
+	people        map[string]string // words of a lowercase alias or name -> member name
+}
+
+type TeamCalendarConfig struct {
+	// WorkWeek overrides the global work week.
+	WorkWeek []string `json:"work_week"`
+
+	// Holidays are in addition to global holidays.
+	Holidays []string `json:"holidays"`
+
+	// Members are keyed by Linear user name.
+	Members map[string]*MemberCalendarConfig `json:"members"`
+
+	workWeek [7]bool
+	holidays map[time.Time]bool
+}
+
+type MemberCalendarConfig struct {
+	PointsPerDay float64 `json:"points_per_day"`
+
+	// TimeOff are dates or inclusive ranges, like holidays.
+	TimeOff []string `json:"time_off"`
+
+	timeOff map[time.Time]bool
+}
+
+func (cc *CalendarConfig) IsEnabled() bool {
+	return len(cc.Teams) > 0
+}
+
+func (cc *CalendarConfig) init() error {
+	var err error
+	cc.workWeek, err = parseWorkWeek(cc.WorkWeek)
+	if err != nil {
+		return err
+	}
+	cc.holidays, err = parseDateList(cc.Holidays)
+	if err != nil {
+		return fmt.Errorf("holidays: %w", err)
+	}
+	if cc.HolidaysICS != "" {
+		events, err := loadICSFile(cc.HolidaysICS)
+		if err != nil {
+			return fmt.Errorf("holidays_ics: %w", err)
+		}
+		for _, ev := range events {
+			for _, d := range ev.Days() {
+				cc.holidays[d] = true
+			}
+		}
+	}
+
+	if cc.TimeOffFilter != "" {
+		if cc.timeOffFilter, err = regexp.Compile(cc.TimeOffFilter); err != nil {
+			return fmt.Errorf("time_off_filter: %w", err)
+		}
+	}
+
+	cc.people = make(map[string]string)
+	for _, tc := range cc.Teams {
+		for name := range tc.Members {
+			cc.people[words(name)] = name
+		}
+	}
+	for alias, name := range cc.Aliases {
+		cc.people[strings.ToLower(alias)] = name
+		if !strings.Contains(alias, "@") {
+			cc.people[words(alias)] = name
+		}
+	}
+
+	for key, tc := range cc.Teams {
+		if tc.WorkWeek == nil {
+			tc.workWeek = cc.workWeek
+		} else if tc.workWeek, err = parseWorkWeek(tc.WorkWeek); err != nil {
+			return fmt.Errorf("teams: %s: %w", key, err)
+		}
+		if tc.holidays, err = parseDateList(tc.Holidays); err != nil {
+			return fmt.Errorf("teams: %s: holidays: %w", key, err)
+		}
+		for name, mc := range tc.Members {
+			if mc.timeOff, err = parseDateList(mc.TimeOff); err != nil {
+				return fmt.Errorf("teams: %s: %s: time_off: %w", key, name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// IsWorkingDay reports whether the date is a working day of the global work
+// week and not a global holiday.
+func (cc *CalendarConfig) IsWorkingDay(d time.Time) bool {
+	if cc.workWeek == [7]bool{} {
+		// not initialized, assume Monday to Friday
+		wd := d.Weekday()
+		return wd != time.Saturday && wd != time.Sunday
+	}
+	return cc.workWeek[d.Weekday()] && !cc.holidays[d]
+}
+
+// CapacityDerivation explains how the capacity of a month was computed.
+type CapacityDerivation struct {
+	Source  CapacitySource
+	Total   float64
+	Members []*MemberCapacity // calendar only
+
+	// TimeOff counts the working days people are away and Deducted is what
+	// was subtracted for them; configured and default capacities only.
+	TimeOff  int
+	Deducted float64
+}
+
+type CapacitySource string
+
+const (
+	CapacityConfigured CapacitySource = "configured"
+	CapacityCalendar   CapacitySource = "calendar"
+	CapacityDefault    CapacitySource = "default"
+)
+
+func (cd *CapacityDerivation) IsCalendar() bool {
+	return cd.Source == CapacityCalendar
+}
+
+// forTeam keeps the members of one team. Configured and default capacities
+// are not split by team and are returned unchanged.
+func (cd *CapacityDerivation) forTeam(key string) *CapacityDerivation {
+	if !cd.IsCalendar() {
+		return cd
+	}
+	result := &CapacityDerivation{Source: cd.Source}
+	for _, mc := range cd.Members {
+		if strings.EqualFold(mc.Team, key) {
+			result.Total += mc.Points
+			result.Members = append(result.Members, mc)
+		}
+	}
+	return result
+}
+
+// MemberCapacity is the contribution of a team member to a month's capacity.
+type MemberCapacity struct {
+	Team         string
+	Name         string
+	PointsPerDay float64
+	WorkingDays  int // days of the team's work week in the month
+	Holidays     int // working days that are holidays
+	TimeOff      int // other working days the member is off
+	Points       float64
+}
+
+func (mc *MemberCapacity) AvailableDays() int {
+	return mc.WorkingDays - mc.Holidays - mc.TimeOff
+}
+
+// Absence is a period of time off of a team member imported from an ICS calendar.
+type Absence struct {
+	Person  string
+	Summary string
+	Start   time.Time // midnight UTC
+	End     time.Time // exclusive
+}
+
+// Overlaps returns whether the absence intersects the given period.
+func (a *Absence) Overlaps(start, end time.Time) bool {
+	return a.Start.Before(end) && start.Before(a.End)
+}
+
+// Covers returns whether the person is away on the given date.
+func (a *Absence) Covers(d time.Time) bool {
+	return !d.Before(a.Start) && d.Before(a.End)
+}
+
+// DateRange formats the days of the absence, e.g. "Jul 7–11".
+func (a *Absence) DateRange() string {
+	last := a.End.AddDate(0, 0, -1)
+	switch {
+	case !last.After(a.Start):
+		return a.Start.Format("Jan 2")
+	case last.Month() == a.Start.Month():
+		return a.Start.Format("Jan 2") + "–" + last.Format("2")
+	default:
+		return a.Start.Format("Jan 2") + "–" + last.Format("Jan 2")
+	}
+}
+
+// loadAbsences imports time off of team members from the configured ICS
+// calendars. Events that cannot be attributed to a member are skipped.
+func (cc *CalendarConfig) loadAbsences() ([]*Absence, error) {
+	var absences []*Absence
+	for _, source := range cc.TimeOffICS {
+		events, err := loadICS(source)
+		if err != nil {
+			return nil, fmt.Errorf("loading time off: %w", err)
+		}
+		for _, ev := range events {
+			if cc.timeOffFilter != nil && !cc.timeOffFilter.MatchString(ev.Summary) {
+				continue
+			}
+			days := ev.Days()
+			if len(days) == 0 {
+				continue
+			}
+			people := cc.matchPeople(ev)
+			if len(people) == 0 {
+				log.Printf("time off: cannot attribute %q on %s to a team member", ev.Summary, days[0].Format("2006-01-02"))
+				continue
+			}
+			for _, person := range people {
+				absences = append(absences, &Absence{
+					Person:  person,
+					Summary: ev.Summary,
+					Start:   days[0],
+					End:     days[len(days)-1].AddDate(0, 0, 1),
+				})
+			}
+		}
+	}
+	slices.SortFunc(absences, func(a, b *Absence) int {
+		return cmp.Or(a.Start.Compare(b.Start), cmp.Compare(a.Person, b.Person))
+	})
+	return absences, nil
+}
+
+// matchPeople returns the team members an event is about: its attendees if
+// any of them are known, otherwise those whose name or alias appears as whole
+// words in the summary.
+func (cc *CalendarConfig) matchPeople(ev *ICSEvent) []string {
+	var people []string
+	for _, att := range ev.Attendees {
+		for _, key := range []string{strings.ToLower(att.Email), words(att.Name)} {
+			if name, ok := cc.people[key]; ok && key != "" && !slices.Contains(people, name) {
+				people = append(people, name)
+			}
+		}
+	}
+	if len(people) > 0 {
+		return people
+	}
+
+	summary := " " + words(ev.Summary) + " "
+	for _, key := range slices.Sorted(maps.Keys(cc.people)) {
+		if name := cc.people[key]; key != "" && strings.Contains(summary, " "+key+" ") && !slices.Contains(people, name) {
+			people = append(people, name)
+		}
+	}
+	return people
+}
+
+// words lowercases s and separates its words by single spaces, dropping
+// punctuation, so that "Ann - PTO" becomes "ann pto".
+func words(s string) string {
+	return strings.Join(strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}), " ")
+}
+
+// deriveCalendarCapacity computes a month's capacity as the sum over team
+// members of points per working day times the days they are available.
+func (cc *CalendarConfig) deriveCalendarCapacity(ym yearmonth.YM, absences []*Absence) *CapacityDerivation {
+	year, month := ym.Components()
+	start := time.Date(year, time.Month(month), 1, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 1, 0)
+
+	result := &CapacityDerivation{Source: CapacityCalendar}
+	for key, tc := range cc.Teams {
+		for name, member := range tc.Members {
+			mc := &MemberCapacity{
+				Team:         key,
+				Name:         name,
+				PointsPerDay: member.PointsPerDay,
+			}
+			for d := start; d.Before(end); d = d.AddDate(0, 0, 1) {
+				switch {
+				case !tc.workWeek[d.Weekday()]:
+					continue
+				case cc.holidays[d] || tc.holidays[d]:
+					mc.Holidays++
+				case member.timeOff[d] || isAbsent(absences, name, d):
+					mc.TimeOff++
+				}
+				mc.WorkingDays++
+			}
+			mc.Points = mc.PointsPerDay * float64(mc.AvailableDays())
+			result.Total += mc.Points
+			result.Members = append(result.Members, mc)
+		}
+	}
+	slices.SortFunc(result.Members, func(a, b *MemberCapacity) int {
+		return cmp.Or(cmp.Compare(a.Team, b.Team), cmp.Compare(a.Name, b.Name))
+	})
+	return result
+}
+
+// deductAbsences reduces a configured or default capacity by PointsPerDay for
+// each working day of the month that someone is away.
+func (cc *CalendarConfig) deductAbsences(cd *CapacityDerivation, ym yearmonth.YM, absences []*Absence) *CapacityDerivation {
+	start := ym.Time()
+	end := start.AddDate(0, 1, 0)
+	var people []string
+	for _, a := range absences {
+		if a.Overlaps(start, end) && !slices.Contains(people, a.Person) {
+			people = append(people, a.Person)
+		}
+	}
+	for d := start; d.Before(end); d = d.AddDate(0, 0, 1) {
+		if !cc.IsWorkingDay(d) {
+			continue
+		}
+		for _, person := range people {
+			if isAbsent(absences, person, d) {
+				cd.TimeOff++
+			}
+		}
+	}
+	cd.Deducted = min(float64(cd.TimeOff)*cc.PointsPerDay, cd.Total)
+	cd.Total -= cd.Deducted
+	return cd
+}
+
+func isAbsent(absences []*Absence, person string, d time.Time) bool {
+	for _, a := range absences {
+		if a.Person == person && a.Covers(d) {
+			return true
+		}
+	}
+	return false
+}
+
+var weekdayNames = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+func parseWorkWeek(days []string) ([7]bool, error) {
+	var result [7]bool
+	if days == nil {
+		days = []string{"Mon", "Tue", "Wed", "Thu", "Fri"}
+	} else if len(days) == 0 {
+		// an all-false work week would read as unset
+		return result, fmt.Errorf("work_week: no working days")
+	}
+	for _, s := range days {
+		wd, ok := weekdayNames[strings.ToLower(s[:min(3, len(s))])]
+		if !ok {
+			return result, fmt.Errorf("invalid weekday %q", s)
+		}
+		result[wd] = true
+	}
+	return result, nil
+}
+
+// parseDateList parses dates ("2025-07-04") and inclusive date ranges
+// ("2025-07-07..2025-07-11") into a set of dates at midnight UTC.
+func parseDateList(items []string) (map[time.Time]bool, error) {
+	result := make(map[time.Time]bool)
+	for _, item := range items {
+		first, last, isRange := strings.Cut(item, "..")
+		start, err := time.Parse("2006-01-02", strings.TrimSpace(first))
+		if err != nil {
+			return nil, fmt.Errorf("invalid date %q", item)
+		}
+		end := start
+		if isRange {
+			end, err = time.Parse("2006-01-02", strings.TrimSpace(last))
+			if err != nil || end.Before(start) {
+				return nil, fmt.Errorf("invalid date range %q", item)
+			}
+		}
+		for d := start; !d.After(end); d = d.AddDate(0, 0, 1) {
+			result[d] = true
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,234 @@
+package main
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prairiegroupinc/linearsummarybot/yearmonth"
+)
+
+func TestDeriveCalendarCapacity(t *testing.T) {
+	cc := CalendarConfig{
+		Holidays: []string{"2025-07-04"},
+		Teams: map[string]*TeamCalendarConfig{
+			"WEB": {
+				Members: map[string]*MemberCalendarConfig{
+					"Ann": {PointsPerDay: 2, TimeOff: []string{"2025-07-03..2025-07-08"}},
+					"Bob": {PointsPerDay: 1},
+				},
+			},
+			"OPS": {
+				WorkWeek: []string{"Mon", "Tue", "Wed", "Thu"},
+				Members: map[string]*MemberCalendarConfig{
+					"Cid": {PointsPerDay: 3},
+				},
+			},
+		},
+	}
+	if err := cc.init(); err != nil {
+		t.Fatal(err)
+	}
+
+	// July 2025 has 23 weekdays, of which 19 are Monday to Thursday; July 4 is a Friday
+	got := cc.deriveCalendarCapacity(yearmonth.Make(2025, 7), nil)
+	want := []MemberCapacity{
+		{Team: "OPS", Name: "Cid", PointsPerDay: 3, WorkingDays: 19, Points: 57},
+		{Team: "WEB", Name: "Ann", PointsPerDay: 2, WorkingDays: 23, Holidays: 1, TimeOff: 3, Points: 38},
+		{Team: "WEB", Name: "Bob", PointsPerDay: 1, WorkingDays: 23, Holidays: 1, Points: 22},
+	}
+	if len(got.Members) != len(want) {
+		t.Fatalf("got %d members, want %d", len(got.Members), len(want))
+	}
+	for i, mc := range got.Members {
+		if *mc != want[i] {
+			t.Errorf("member %d = %+v, want %+v", i, *mc, want[i])
+		}
+	}
+	if got.Total != 57+38+22 {
+		t.Errorf("Total = %v, want %v", got.Total, 57+38+22)
+	}
+
+	for _, empty := range []CalendarConfig{
+		{WorkWeek: []string{}},
+		{Teams: map[string]*TeamCalendarConfig{"OPS": {WorkWeek: []string{}}}},
+	} {
+		if err := empty.init(); err == nil {
+			t.Errorf("init() accepted an empty work_week: %+v", empty)
+		}
+	}
+
+	web := got.forTeam("web")
+	if len(web.Members) != 2 || web.Total != 38+22 {
+		t.Errorf("forTeam(web) = %v from %d members, want %v from 2", web.Total, len(web.Members), 38+22)
+	}
+	configured := &CapacityDerivation{Source: CapacityConfigured, Total: 80}
+	if configured.forTeam("WEB").Total != 80 {
+		t.Error("forTeam should not split configured capacity")
+	}
+}
+
+func TestParseICS(t *testing.T) {
+	const ics = "BEGIN:VCALENDAR\r\n" +
+		"VERSION:2.0\r\n" +
+		"BEGIN:VEVENT\r\n" +
+		"DTSTART;VALUE=DATE:20251224\r\n" +
+		"DTEND;VALUE=DATE:20251227\r\n" +
+		"SUMMARY:Christmas\\, and the\r\n" +
+		"  day after\r\n" +
+		"END:VEVENT\r\n" +
+		"BEGIN:VEVENT\r\n" +
+		"DTSTART;VALUE=DATE:20250704\r\n" +
+		"SUMMARY:Independence Day\r\n" +
+		"END:VEVENT\r\n" +
+		"BEGIN:VEVENT\r\n" +
+		"DTSTART:20250801T090000Z\r\n" +
+		"DTEND:20250801T170000Z\r\n" +
+		"SUMMARY:Offsite\r\n" +
+		"END:VEVENT\r\n" +
+		"END:VCALENDAR\r\n"
+
+	events, err := parseICS(strings.NewReader(ics))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 3 {
+		t.Fatalf("got %d events, want 3", len(events))
+	}
+	if events[0].Summary != "Christmas, and the day after" {
+		t.Errorf("Summary = %q", events[0].Summary)
+	}
+
+	date := func(year, month, day int) time.Time {
+		return time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+	}
+	wantDays := [][]time.Time{
+		{date(2025, 12, 24), date(2025, 12, 25), date(2025, 12, 26)},
+		{date(2025, 7, 4)},
+		{date(2025, 8, 1)},
+	}
+	for i, ev := range events {
+		days := ev.Days()
+		if len(days) != len(wantDays[i]) {
+			t.Errorf("event %d days = %v, want %v", i, days, wantDays[i])
+			continue
+		}
+		for j := range days {
+			if !days[j].Equal(wantDays[i][j]) {
+				t.Errorf("event %d days = %v, want %v", i, days, wantDays[i])
+			}
+		}
+	}
+
+	// Long unfolded lines are read whole, and lines over the limit are an error
+	long := strings.Replace(ics, "SUMMARY:Offsite\r\n", "SUMMARY:Offsite\r\nDESCRIPTION:"+strings.Repeat("x", 2<<20)+"\r\n", 1)
+	if events, err := parseICS(strings.NewReader(long)); err != nil || len(events) != 3 {
+		t.Errorf("long line: got %d events, %v, want 3", len(events), err)
+	}
+	tooLong := "BEGIN:VCALENDAR\r\nDESCRIPTION:" + strings.Repeat("x", maxICSLine) + "\r\n"
+	if _, err := parseICS(strings.NewReader(tooLong)); err == nil {
+		t.Error("parseICS should fail on a line over the limit")
+	}
+}
+
+func TestLoadAbsences(t *testing.T) {
+	const ics = "BEGIN:VCALENDAR\n" +
+		"BEGIN:VEVENT\n" +
+		"DTSTART;VALUE=DATE:20250707\n" +
+		"DTEND;VALUE=DATE:20250712\n" +
+		"SUMMARY:Vacation\n" +
+		"ATTENDEE;CN=Ann Smith:mailto:Ann@Example.com\n" +
+		"END:VEVENT\n" +
+		"BEGIN:VEVENT\n" +
+		"DTSTART;VALUE=DATE:20250721\n" +
+		"SUMMARY:Bob - PTO\n" +
+		"END:VEVENT\n" +
+		"BEGIN:VEVENT\n" +
+		"DTSTART;VALUE=DATE:20250722\n" +
+		"SUMMARY:Bob - dentist\n" +
+		"END:VEVENT\n" +
+		"BEGIN:VEVENT\n" +
+		"DTSTART;VALUE=DATE:20250723\n" +
+		"SUMMARY:Vacation of someone else\n" +
+		"END:VEVENT\n" +
+		"BEGIN:VEVENT\n" +
+		"DTSTART;VALUE=DATE:20250724\n" +
+		"SUMMARY:Annual planning (no PTO)\n" +
+		"END:VEVENT\n" +
+		"END:VCALENDAR\n"
+	path := t.TempDir() + "/pto.ics"
+	if err := os.WriteFile(path, []byte(ics), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cc := CalendarConfig{
+		TimeOffICS:    []string{path},
+		TimeOffFilter: "(?i)vacation|pto",
+		Aliases:       map[string]string{"ann@example.com": "Ann"},
+		Teams: map[string]*TeamCalendarConfig{
+			"WEB": {
+				Members: map[string]*MemberCalendarConfig{
+					"Ann": {PointsPerDay: 2},
+					"Bob": {PointsPerDay: 1},
+				},
+			},
+		},
+	}
+	if err := cc.init(); err != nil {
+		t.Fatal(err)
+	}
+
+	absences, err := cc.loadAbsences()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(absences) != 2 {
+		t.Fatalf("got %d absences, want 2", len(absences))
+	}
+	if a := absences[0]; a.Person != "Ann" || a.DateRange() != "Jul 7–11" {
+		t.Errorf("absence 0 = %s %s, want Ann Jul 7–11", a.Person, a.DateRange())
+	}
+	if a := absences[1]; a.Person != "Bob" || a.DateRange() != "Jul 21" {
+		t.Errorf("absence 1 = %s %s, want Bob Jul 21", a.Person, a.DateRange())
+	}
+
+	// July 2025 has 23 working days
+	got := cc.deriveCalendarCapacity(yearmonth.Make(2025, 7), absences)
+	if got.Total != 2*(23-5)+1*(23-1) {
+		t.Errorf("Total = %v, want %v", got.Total, 2*(23-5)+1*(23-1))
+	}
+}
+
+func TestDeductAbsences(t *testing.T) {
+	date := func(month, day int) time.Time {
+		return time.Date(2025, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+	}
+	absences := []*Absence{
+		{Person: "Ann", Start: date(7, 7), End: date(7, 12)},  // Mon to Fri
+		{Person: "Ann", Start: date(7, 11), End: date(7, 12)}, // same day twice
+		{Person: "Bob", Start: date(7, 11), End: date(7, 14)}, // Fri and the weekend
+		{Person: "Bob", Start: date(6, 30), End: date(7, 2)},  // Mon in June, Tue in July
+		{Person: "Cat", Start: date(8, 1), End: date(8, 2)},   // another month
+	}
+
+	tests := []struct {
+		perDay   float64
+		capacity float64
+		total    float64
+	}{
+		{0, 160, 160},
+		{2, 160, 160 - 2*7},
+		{30, 160, 0},
+	}
+	for _, tt := range tests {
+		cc := CalendarConfig{PointsPerDay: tt.perDay}
+		if err := cc.init(); err != nil {
+			t.Fatal(err)
+		}
+		cd := cc.deductAbsences(&CapacityDerivation{Source: CapacityConfigured, Total: tt.capacity}, yearmonth.Make(2025, 7), absences)
+		if cd.TimeOff != 7 || cd.Total != tt.total || cd.Deducted != tt.capacity-tt.total {
+			t.Errorf("points_per_day %v: TimeOff = %d, Total = %v, Deducted = %v, want 7, %v, %v", tt.perDay, cd.TimeOff, cd.Total, cd.Deducted, tt.total, tt.capacity-tt.total)
+		}
+	}
+}
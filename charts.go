@@ -0,0 +1,348 @@
+package main
+
+import (
+	"bytes"
+	"cmp"
+	"fmt"
+	"html/template"
+	"io"
+	"math"
+	"slices"
+)
+
+func init() {
+	registerFormat(&Format{Name: "svg", Extension: "svg", ContentType: "image/svg+xml", Renderer: RenderFunc(renderChart)})
+}
+
+// defaultChartMonths is how many months the initiative chart shows by default.
+const defaultChartMonths = 6
+
+// Colors of schedules in charts, from most to least committed.
+const (
+	fixedColor   = "#4f46e5"
+	plannedColor = "#818cf8"
+	flexColor    = "#c7d2fe"
+	limitColor   = "#b91c1c"
+)
+
+// chartPalette colors the initiatives of the initiative chart, in order of size.
+var chartPalette = []string{"#4f46e5", "#0891b2", "#16a34a", "#ca8a04", "#ea580c", "#db2777", "#7c3aed", "#0d9488", "#65a30d"}
+
+// restColor colors the initiatives that did not get a palette color.
+const restColor = "#d1d5db"
+
+// renderChart writes the chart selected by opts.Chart as a standalone SVG image.
+func renderChart(w io.Writer, report *Report, opts RenderOptions) error {
+	if err := validateChart(opts.Chart); err != nil {
+		return err
+	}
+	switch opts.Chart {
+	case "initiatives":
+		return writeBarChart(w, initiativeChart(report, cmp.Or(opts.ChartMonths, defaultChartMonths)))
+	default:
+		return writeBarChart(w, capacityChart(report))
+	}
+}
+
+// validateChart checks a chart name; empty selects the capacity chart.
+func validateChart(name string) error {
+	switch name {
+	case "", "capacity", "initiatives":
+		return nil
+	default:
+		return fmt.Errorf("unknown chart %q, expected capacity or initiatives", name)
+	}
+}
+
+// BarChart is a stacked bar chart with a bar per month and an optional limit
+// line, like capacity, drawn across each bar.
+type BarChart struct {
+	Title  string
+	Unit   Unit
+	Bars   []*ChartBar
+	Legend []*ChartSegment // Value is ignored
+}
+
+type ChartBar struct {
+	Label    string
+	Segments []*ChartSegment // bottom to top
+	Limit    float64         // 0 for none
+}
+
+type ChartSegment struct {
+	Name  string
+	Value float64
+	Color string
+}
+
+func (bar *ChartBar) Total() float64 {
+	var total float64
+	for _, s := range bar.Segments {
+		total += s.Value
+	}
+	return total
+}
+
+// capacityChart stacks Fixed, Planned and Flex work of every month against its capacity.
+func capacityChart(report *Report) *BarChart {
+	chart := &BarChart{
+		Title: "Capacity",
+		Unit:  report.Unit,
+		Legend: []*ChartSegment{
+			{Name: Fixed.String(), Color: fixedColor},
+			{Name: Planned.String(), Color: plannedColor},
+			{Name: Flex.String(), Color: flexColor},
+			{Name: "Capacity", Color: limitColor},
+		},
+	}
+	for _, md := range report.Months {
+		chart.Bars = append(chart.Bars, &ChartBar{
+			Label: md.Key.Time().Format("Jan 2006"),
+			Segments: []*ChartSegment{
+				{Name: Fixed.String(), Value: md.Fixed, Color: fixedColor},
+				{Name: Planned.String(), Value: md.Planned, Color: plannedColor},
+				{Name: Flex.String(), Value: md.Flex, Color: flexColor},
+			},
+			Limit: md.Capacity,
+		})
+	}
+	return chart
+}
+
+// initiativeChart stacks the work of each initiative (or pivot value) over
+// the next n months, starting with the current one. The largest initiatives
+// get their own color; the rest are stacked together.
+func initiativeChart(report *Report, n int) *BarChart {
+	var months []*MonthData
+	for _, md := range report.Months {
+		if !md.IsPast && len(months) < n {
+			months = append(months, md)
+		}
+	}
+
+	totals := make(map[string]float64)
+	for _, md := range months {
+		for _, idata := range md.SortedInitiatives {
+			totals[idata.Name] += idata.Used
+		}
+	}
+	stack := newNamedStack(totals)
+
+	chart := &BarChart{
+		Title:  fmt.Sprintf("%s, next %d months", cmp.Or(report.Pivot, "Initiatives"), len(months)),
+		Unit:   report.Unit,
+		Legend: stack.legend(),
+	}
+	for _, md := range months {
+		used := make(map[string]float64)
+		for name, idata := range md.Initiatives {
+			used[name] = idata.Used
+		}
+		chart.Bars = append(chart.Bars, &ChartBar{
+			Label:    md.Key.Time().Format("Jan 2006"),
+			Segments: stack.segments(used),
+			Limit:    md.Capacity,
+		})
+	}
+	return chart
+}
+
+// namedStack assigns colors to the names stacked in a chart, like
+// initiatives. The largest get a color of their own, the rest are stacked
+// together.
+type namedStack struct {
+	names  []string // largest first
+	colors map[string]string
+}
+
+// newNamedStack orders names by their totals, skipping those with none.
+func newNamedStack(totals map[string]float64) *namedStack {
+	stack := &namedStack{colors: make(map[string]string)}
+	for name, total := range totals {
+		if total > 0 {
+			stack.names = append(stack.names, name)
+		}
+	}
+	slices.SortFunc(stack.names, func(a, b string) int {
+		return cmp.Or(cmp.Compare(totals[b], totals[a]), cmp.Compare(a, b))
+	})
+	for i, name := range stack.names {
+		if i == len(chartPalette) {
+			break
+		}
+		stack.colors[name] = chartPalette[i]
+	}
+	return stack
+}
+
+// legend lists the colored names, the rest if any, and the capacity line.
+func (stack *namedStack) legend() []*ChartSegment {
+	var legend []*ChartSegment
+	for _, name := range stack.names {
+		if color, ok := stack.colors[name]; ok {
+			legend = append(legend, &ChartSegment{Name: name, Color: color})
+		}
+	}
+	if len(stack.names) > len(chartPalette) {
+		legend = append(legend, &ChartSegment{Name: "Rest", Color: restColor})
+	}
+	return append(legend, &ChartSegment{Name: "Capacity", Color: limitColor})
+}
+
+// segments stacks the values of a bar, largest names at the bottom.
+func (stack *namedStack) segments(values map[string]float64) []*ChartSegment {
+	var segments []*ChartSegment
+	var rest float64
+	for _, name := range stack.names {
+		v := values[name]
+		if v == 0 {
+			continue
+		}
+		if color, ok := stack.colors[name]; ok {
+			segments = append(segments, &ChartSegment{Name: name, Value: v, Color: color})
+		} else {
+			rest += v
+		}
+	}
+	if rest > 0 {
+		segments = append(segments, &ChartSegment{Name: "Rest", Value: rest, Color: restColor})
+	}
+	return segments
+}
+
+// Chart geometry, in SVG user units.
+const (
+	chartWidth   = 640
+	chartHeight  = 280
+	chartTop     = 32 // title
+	chartBottom  = 56 // month labels and legend
+	chartLeft    = 48 // axis labels
+	chartRight   = 16
+	chartTicks   = 4
+	legendHeight = 20
+)
+
+// writeBarChart draws the chart as a self-contained SVG document: no
+// scripts, stylesheets or fonts, so that it can be embedded into pages and
+// emails or uploaded as an image.
+func writeBarChart(w io.Writer, chart *BarChart) error {
+	legend, rows := layoutLegend(chart.Legend)
+	height := chartHeight + max(rows-1, 0)*legendHeight
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, `<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 %d %d" width="%d" height="%d" style="max-width: 100%%; height: auto" font-family="Helvetica, Arial, sans-serif" font-size="11">`, chartWidth, height, chartWidth, height)
+	fmt.Fprintf(&buf, `<title>%s</title>`, xmlEscape(chart.Title))
+	fmt.Fprintf(&buf, `<rect width="%d" height="%d" fill="#fff"/>`, chartWidth, height)
+	fmt.Fprintf(&buf, `<text x="%d" y="20" font-size="14" font-weight="600" fill="#1f2937">%s</text>`, chartLeft, xmlEscape(chart.Title))
+
+	var top float64
+	for _, bar := range chart.Bars {
+		top = max(top, bar.Total(), bar.Limit)
+	}
+	step := niceStep(top / chartTicks)
+	top = step * math.Max(1, math.Ceil(top/step))
+
+	plotHeight := float64(chartHeight - chartTop - chartBottom)
+	plotWidth := float64(chartWidth - chartLeft - chartRight)
+	baseline := float64(chartHeight - chartBottom)
+	y := func(v float64) float64 {
+		return baseline - v/top*plotHeight
+	}
+
+	for v := 0.0; v <= top+step/2; v += step {
+		fmt.Fprintf(&buf, `<line x1="%d" x2="%d" y1="%.1f" y2="%.1f" stroke="#e5e7eb"/>`, chartLeft, chartWidth-chartRight, y(v), y(v))
+		fmt.Fprintf(&buf, `<text x="%d" y="%.1f" text-anchor="end" fill="#6b7280">%s</text>`, chartLeft-6, y(v)+4, formatPoints(v))
+	}
+	fmt.Fprintf(&buf, `<text x="%d" y="%d" text-anchor="end" fill="#6b7280">%s</text>`, chartLeft-6, chartTop-6, chart.Unit.Short())
+
+	if len(chart.Bars) > 0 {
+		slot := plotWidth / float64(len(chart.Bars))
+		barWidth := math.Min(slot*0.6, 64)
+		for i, bar := range chart.Bars {
+			x := chartLeft + slot*float64(i) + (slot-barWidth)/2
+			var stacked float64
+			for _, s := range bar.Segments {
+				if s.Value <= 0 {
+					continue
+				}
+				fmt.Fprintf(&buf, `<rect x="%.1f" y="%.1f" width="%.1f" height="%.1f" fill="%s"><title>%s: %s %s</title></rect>`,
+					x, y(stacked+s.Value), barWidth, y(stacked)-y(stacked+s.Value), s.Color, xmlEscape(s.Name), formatPoints(s.Value), chart.Unit.Short())
+				stacked += s.Value
+			}
+			if bar.Limit > 0 {
+				fmt.Fprintf(&buf, `<line x1="%.1f" x2="%.1f" y1="%.1f" y2="%.1f" stroke="%s" stroke-width="2" stroke-dasharray="4 2"><title>Capacity: %s %s</title></line>`,
+					x-4, x+barWidth+4, y(bar.Limit), y(bar.Limit), limitColor, formatPoints(bar.Limit), chart.Unit.Short())
+			}
+			fmt.Fprintf(&buf, `<text x="%.1f" y="%.1f" text-anchor="middle" fill="#374151">%s</text>`, x+barWidth/2, baseline+16, xmlEscape(bar.Label))
+		}
+	}
+
+	for _, item := range legend {
+		legendY := float64(chartHeight - legendHeight/2 + item.row*legendHeight)
+		fmt.Fprintf(&buf, `<rect x="%.1f" y="%.1f" width="10" height="10" fill="%s"><title>%s</title></rect>`, item.x, legendY-8, item.Color, xmlEscape(item.Name))
+		fmt.Fprintf(&buf, `<text x="%.1f" y="%.1f" fill="#374151">%s</text>`, item.x+14, legendY+1, xmlEscape(item.label))
+	}
+
+	buf.WriteString(`</svg>`)
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// maxLegendName is the number of characters legend names are truncated to.
+const maxLegendName = 28
+
+// legendItem is a legend entry placed by layoutLegend.
+type legendItem struct {
+	*ChartSegment
+	label string // the name, truncated
+	x     float64
+	row   int
+}
+
+// layoutLegend places legend entries from left to right, wrapping them onto
+// new rows so that they fit the width of the chart. It returns the entries
+// and the number of rows.
+func layoutLegend(legend []*ChartSegment) ([]legendItem, int) {
+	var items []legendItem
+	x, row := float64(chartLeft), 0
+	for _, s := range legend {
+		label := s.Name
+		if runes := []rune(label); len(runes) > maxLegendName {
+			label = string(runes[:maxLegendName-1]) + "…"
+		}
+		width := 14 + 6.5*float64(len([]rune(label)))
+		if x > chartLeft && x+width > chartWidth-chartRight {
+			x, row = chartLeft, row+1
+		}
+		items = append(items, legendItem{ChartSegment: s, label: label, x: x, row: row})
+		x += width + 16
+	}
+	if len(items) == 0 {
+		return nil, 0
+	}
+	return items, row + 1
+}
+
+// niceStep rounds a raw axis step up to 1, 2 or 5 times a power of ten.
+func niceStep(raw float64) float64 {
+	if raw <= 0 {
+		return 1
+	}
+	magnitude := math.Pow(10, math.Floor(math.Log10(raw)))
+	for _, m := range []float64{1, 2, 5} {
+		if raw <= m*magnitude {
+			return m * magnitude
+		}
+	}
+	return 10 * magnitude
+}
+
+// chartHTML renders a chart for embedding into HTML views, as in
+// {{capacityChart .Report}} or {{initiativeChart .Report 6}}.
+func chartHTML(chart *BarChart) (template.HTML, error) {
+	var buf bytes.Buffer
+	if err := writeBarChart(&buf, chart); err != nil {
+		return "", err
+	}
+	return template.HTML(buf.String()), nil
+}
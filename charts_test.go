@@ -0,0 +1,126 @@
+package main
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/prairiegroupinc/linearsummarybot/yearmonth"
+)
+
+func TestInitiativeChart(t *testing.T) {
+	var months []*MonthData
+	for i, m := range []int{1, 2, 3, 4} {
+		md := &MonthData{
+			Key:         yearmonth.Make(2025, m),
+			IsPast:      i == 0,
+			Capacity:    100,
+			Initiatives: make(map[string]*InitiativeData),
+		}
+		for j := range 11 {
+			name := fmt.Sprintf("I%02d", j)
+			idata := &InitiativeData{Name: name, Used: float64(j + 1)}
+			md.Initiatives[name] = idata
+			md.SortedInitiatives = append(md.SortedInitiatives, idata)
+		}
+		months = append(months, md)
+	}
+	report := &Report{Months: months}
+
+	chart := initiativeChart(report, 2)
+	if len(chart.Bars) != 2 || chart.Bars[0].Label != "Feb 2025" || chart.Bars[1].Label != "Mar 2025" {
+		t.Fatalf("bars = %v, want Feb and Mar 2025", chart.Bars)
+	}
+
+	var legend []string
+	for _, s := range chart.Legend {
+		legend = append(legend, s.Name)
+	}
+	if got, want := strings.Join(legend, ","), "I10,I09,I08,I07,I06,I05,I04,I03,I02,Rest,Capacity"; got != want {
+		t.Errorf("legend = %s, want %s", got, want)
+	}
+
+	bar := chart.Bars[0]
+	if n := len(bar.Segments); n != len(chartPalette)+1 {
+		t.Fatalf("segments = %d, want %d", n, len(chartPalette)+1)
+	}
+	if rest := bar.Segments[len(bar.Segments)-1]; rest.Name != "Rest" || rest.Value != 1+2 {
+		t.Errorf("rest = %s %v, want Rest 3", rest.Name, rest.Value)
+	}
+	if got := bar.Total(); got != 66 {
+		t.Errorf("total = %v, want 66", got)
+	}
+}
+
+func TestWriteBarChart(t *testing.T) {
+	report := newMockReport()
+	report.Months[0].Capacity = 100
+	for _, chart := range []*BarChart{capacityChart(report), initiativeChart(report, 6), {Title: "Empty"}} {
+		var sb strings.Builder
+		if err := writeBarChart(&sb, chart); err != nil {
+			t.Fatal(err)
+		}
+		svg := sb.String()
+		d := xml.NewDecoder(strings.NewReader(svg))
+		for {
+			_, err := d.Token()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				t.Fatalf("%s: invalid SVG: %v\n%s", chart.Title, err, svg)
+			}
+		}
+		if strings.Contains(svg, "NaN") || strings.Contains(svg, "Inf") {
+			t.Errorf("%s: invalid coordinates:\n%s", chart.Title, svg)
+		}
+	}
+}
+
+func TestNiceStep(t *testing.T) {
+	tests := []struct {
+		raw, want float64
+	}{
+		{0, 1},
+		{0.3, 0.5},
+		{1, 1},
+		{1.2, 2},
+		{3, 5},
+		{7, 10},
+		{20, 20},
+		{26, 50},
+		{120, 200},
+	}
+	for _, tt := range tests {
+		if got := niceStep(tt.raw); got != tt.want {
+			t.Errorf("niceStep(%v) = %v, want %v", tt.raw, got, tt.want)
+		}
+	}
+}
+
+func TestLayoutLegend(t *testing.T) {
+	var legend []*ChartSegment
+	for _, name := range []string{"Reliability", "Last Minute", "Customer onboarding revamp", "AG MVP", "Mobile app v2", "Payments", "Search relevance improvements for enterprise", "Growth", "Infra", "Rest", "Capacity"} {
+		legend = append(legend, &ChartSegment{Name: name})
+	}
+	items, rows := layoutLegend(legend)
+	if rows < 2 {
+		t.Errorf("rows = %d, want the legend to wrap", rows)
+	}
+	for _, item := range items {
+		if right := item.x + 14 + 6.5*float64(len([]rune(item.label))); right > chartWidth-chartRight {
+			t.Errorf("%s ends at %v, past the chart width", item.label, right)
+		}
+		if len([]rune(item.label)) > maxLegendName {
+			t.Errorf("%s is not truncated", item.label)
+		}
+	}
+	if items[6].label != "Search relevance improvemen…" {
+		t.Errorf("truncated label = %q", items[6].label)
+	}
+	if _, rows := layoutLegend(nil); rows != 0 {
+		t.Errorf("empty legend has %d rows", rows)
+	}
+}
@@ -3,7 +3,13 @@ package main
 import (
 	_ "embed"
 	"encoding/json"
+	"fmt"
 	"log"
+	"maps"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
 
 	"github.com/andreyvit/jsonfix"
 	"github.com/prairiegroupinc/linearsummarybot/yearmonth"
@@ -15,15 +21,335 @@ var StatesToSkip = map[string]struct{}{}
 var configJSON []byte
 
 type AppConfig struct {
-	StatesToSkip    []string                      `json:"states_to_skip"`
-	TagsToBuckets   map[string]string             `json:"tags_to_buckets"`
-	DefaultCapacity int                           `json:"default_capacity"`
-	ByMonth         map[yearmonth.YM]*MonthConfig `json:"months"`
+	StatesToSkip          []string                        `json:"states_to_skip"`
+	TagsToBuckets         map[string]string               `json:"tags_to_buckets"`
+	DefaultCapacity       int                             `json:"default_capacity"`
+	EstimateScales        map[string]*EstimateScaleConfig `json:"estimate_scales"`
+	DefaultBudget         map[string]BudgetAmount         `json:"default_budget"`
+	CarryOver             map[string]*CarryOverConfig     `json:"carry_over"`
+	ByMonth               map[yearmonth.YM]*MonthConfig   `json:"months"`
+	InitiativeAttribution AttributionConfig               `json:"initiative_attribution"`
+	MonthAttribution      MonthAttributionConfig          `json:"month_attribution"`
+	Clients               map[string]*ClientConfig        `json:"clients"`
+	Dimensions            []*DimensionConfig              `json:"dimensions"`
+	Calendar              CalendarConfig                  `json:"calendar"`
+	Risk                  RiskConfig                      `json:"risk"`
+	Hours                 HoursConfig                     `json:"hours"`
+
+	// SnapshotsDir is where a snapshot of each report is recorded for trend
+	// charts, one file per day; snapshots are off if empty.
+	SnapshotsDir string `json:"snapshots_dir"`
+
+	Email EmailConfig `json:"email"`
+
+	// MilestoneDueDates makes issues without a due date use the target date of their project milestone.
+	MilestoneDueDates bool `json:"milestone_due_dates"`
+}
+
+// ClientDimension is the dimension whose values are treated as clients.
+const ClientDimension = "Client"
+
+// BucketDimension is the dimension whose values are budget buckets. Unless
+// configured explicitly, it is derived from tags_to_buckets.
+const BucketDimension = "Bucket"
+
+// defaultDimensions are used when config.json does not list any dimensions.
+var defaultDimensions = []*DimensionConfig{
+	{Name: ClientDimension, Prefix: "Client-"},
+}
+
+// DimensionConfig defines a reporting dimension whose values come from issue
+// labels. Exactly one of Prefix, Regex, Parent and Labels must be set.
+type DimensionConfig struct {
+	Name string `json:"name"`
+
+	// Prefix matches labels starting with it; the value is the rest of the label.
+	Prefix string `json:"prefix"`
+
+	// Regex matches labels against a regular expression; the value is the first
+	// capture group, or the whole label if there are no groups.
+	Regex string `json:"regex"`
+
+	// Parent matches labels inside a Linear label group; the value is the label name.
+	Parent string `json:"parent"`
+
+	// Labels maps exact label names to values, like tags_to_buckets.
+	Labels map[string]string `json:"labels"`
+
+	regex *regexp.Regexp
+}
+
+func (dc *DimensionConfig) init() error {
+	if dc.Name == "" {
+		return fmt.Errorf("missing name")
+	}
+	n := 0
+	for _, s := range []string{dc.Prefix, dc.Regex, dc.Parent} {
+		if s != "" {
+			n++
+		}
+	}
+	if len(dc.Labels) > 0 {
+		n++
+	}
+	if n != 1 {
+		return fmt.Errorf("%s: exactly one of prefix, regex, parent and labels must be set", dc.Name)
+	}
+	if dc.Regex != "" {
+		var err error
+		dc.regex, err = regexp.Compile(dc.Regex)
+		if err != nil {
+			return fmt.Errorf("%s: %w", dc.Name, err)
+		}
+	}
+	return nil
+}
+
+// Match returns the dimension value for a label with the given parent label group.
+func (dc *DimensionConfig) Match(label, parent string) (string, bool) {
+	switch {
+	case dc.Prefix != "":
+		if value, ok := strings.CutPrefix(label, dc.Prefix); ok {
+			return value, true
+		}
+		return "", false
+	case dc.regex != nil:
+		m := dc.regex.FindStringSubmatch(label)
+		if m == nil {
+			return "", false
+		}
+		if len(m) > 1 {
+			return m[1], m[1] != ""
+		}
+		return label, true
+	case dc.Parent != "":
+		if parent != dc.Parent {
+			return "", false
+		}
+		return label, true
+	case dc.Labels != nil:
+		value, ok := dc.Labels[label]
+		return value, ok && value != ""
+	default:
+		return "", false
+	}
+}
+
+func (c *AppConfig) Dimension(name string) *DimensionConfig {
+	for _, dc := range c.Dimensions {
+		if strings.EqualFold(dc.Name, name) {
+			return dc
+		}
+	}
+	return nil
+}
+
+// AttributionConfig decides how an issue whose project belongs to several
+// initiatives is attributed to them.
+type AttributionConfig struct {
+	// Mode is one of AttributePrimary (default), AttributeEven or AttributeWeighted.
+	Mode AttributionMode `json:"mode"`
+
+	// Priority lists initiatives in order of preference for AttributePrimary.
+	// Initiatives not listed rank below all listed ones, in Linear's order.
+	Priority []string `json:"priority"`
+
+	// Weights are relative weights for AttributeWeighted; unlisted initiatives weigh 1.
+	Weights map[string]float64 `json:"weights"`
+}
+
+type AttributionMode string
+
+// MonthAttributionConfig decides how issues are attributed to months.
+type MonthAttributionConfig struct {
+	// Mode is either SpreadToTargetMonth (default) or SpreadByWorkingDays.
+	Mode MonthAttributionMode `json:"mode"`
+
+	// SpreadFlexFrom is the number of points from which Flex issues are spread
+	// between now and their due date in SpreadByWorkingDays mode; 0 disables it.
+	SpreadFlexFrom float64 `json:"spread_flex_from"`
+}
+
+type MonthAttributionMode string
+
+const (
+	// SpreadToTargetMonth attributes the whole issue to the month of its target date.
+	SpreadToTargetMonth MonthAttributionMode = "target"
+
+	// SpreadByWorkingDays splits issues in a cycle across months by the
+	// fraction of the cycle's working days in each month.
+	SpreadByWorkingDays MonthAttributionMode = "spread"
+)
+
+const (
+	AttributePrimary  AttributionMode = "primary"
+	AttributeEven     AttributionMode = "even"
+	AttributeWeighted AttributionMode = "weighted"
+)
+
+// ClientConfig describes the retainer of a client identified by a Client-XXX label.
+type ClientConfig struct {
+	// Retainer is the number of points, or hours if Unit is UnitHours, per
+	// month contracted by the client.
+	Retainer int `json:"retainer"`
+
+	// Unit is the unit of Retainer and ByMonth; points by default.
+	Unit Unit `json:"unit"`
+
+	// ByMonth overrides Retainer for specific months.
+	ByMonth map[yearmonth.YM]int `json:"months"`
+}
+
+func (cc *ClientConfig) BudgetFor(ym yearmonth.YM) int {
+	if cc == nil {
+		return 0
+	}
+	if v, ok := cc.ByMonth[ym]; ok {
+		return v
+	}
+	return cc.Retainer
+}
+
+// retainer returns the retainer for a month in the unit of the report.
+// Retainers in hours are converted into points using hours.per_point.
+func (cc *ClientConfig) retainer(ym yearmonth.YM, conv conversion) float64 {
+	amount := float64(cc.BudgetFor(ym))
+	if cc != nil && cc.Unit == UnitHours {
+		return conv.fromHours(amount)
+	}
+	return amount * conv.perPoint()
 }
 
 type MonthConfig struct {
-	Capacity int            `json:"capacity"`
-	Budget   map[string]int `json:"budget"`
+	Capacity int                     `json:"capacity"`
+	Budget   map[string]BudgetAmount `json:"budget"`
+}
+
+// BudgetAmount is a budget in points (40) or in percent of the month's capacity ("25%").
+type BudgetAmount struct {
+	Points  float64
+	Percent float64
+}
+
+// Resolve returns the budget in points for a month of the given capacity.
+func (b BudgetAmount) Resolve(capacity, perPoint float64) float64 {
+	if b.Percent != 0 {
+		return capacity * b.Percent / 100
+	}
+	return b.Points * perPoint
+}
+
+func (b BudgetAmount) MarshalJSON() ([]byte, error) {
+	if b.Percent != 0 {
+		return json.Marshal(strconv.FormatFloat(b.Percent, 'f', -1, 64) + "%")
+	}
+	return json.Marshal(b.Points)
+}
+
+func (b *BudgetAmount) UnmarshalJSON(data []byte) error {
+	var s string
+	if json.Unmarshal(data, &s) != nil {
+		*b = BudgetAmount{}
+		if err := json.Unmarshal(data, &b.Points); err != nil {
+			return err
+		}
+		if b.Points < 0 {
+			return fmt.Errorf("negative budget %s", data)
+		}
+		return nil
+	}
+	v, err := strconv.ParseFloat(strings.TrimSpace(strings.TrimSuffix(s, "%")), 64)
+	if err != nil || !strings.HasSuffix(s, "%") {
+		return fmt.Errorf("invalid budget %q, expected points or a percentage like \"25%%\"", s)
+	}
+	if v < 0 {
+		return fmt.Errorf("negative budget %q", s)
+	}
+	*b = BudgetAmount{Percent: v}
+	return nil
+}
+
+// validateBudgets rejects budget templates whose percentages add up to more
+// than 100%, and months whose budgets exceed their configured capacity.
+func (c *AppConfig) validateBudgets() error {
+	check := func(amounts map[string]BudgetAmount, capacity float64) error {
+		var percent, total float64
+		for _, amount := range amounts {
+			percent += amount.Percent
+			total += amount.Resolve(capacity, 1)
+		}
+		if percent > 100 {
+			return fmt.Errorf("budgets add up to %s%% of capacity", formatPoints(percent))
+		}
+		if capacity > 0 && total > capacity {
+			return fmt.Errorf("budgets add up to %s, more than the capacity of %s", formatPoints(total), formatPoints(capacity))
+		}
+		return nil
+	}
+	if err := check(c.DefaultBudget, 0); err != nil {
+		return fmt.Errorf("default_budget: %w", err)
+	}
+	for _, ym := range slices.Sorted(maps.Keys(c.ByMonth)) {
+		mc := c.ByMonth[ym]
+		capacity := float64(mc.Capacity)
+		if capacity == 0 && mc.Budget == nil {
+			continue // budgets depend on the calendar or default capacity
+		}
+		amounts := mc.Budget
+		if amounts == nil {
+			amounts = c.DefaultBudget
+		}
+		if err := check(amounts, capacity); err != nil {
+			return fmt.Errorf("months: %v: %w", ym, err)
+		}
+	}
+	return nil
+}
+
+// monthBudget returns the budgets of a month in the unit of the capacity, using
+// DefaultBudget for months that do not specify any. Budgets in points are
+// scaled by perPoint.
+func (c *AppConfig) monthBudget(ym yearmonth.YM, capacity, perPoint float64) map[string]float64 {
+	amounts := c.DefaultBudget
+	if mc := c.ByMonth[ym]; mc != nil && mc.Budget != nil {
+		amounts = mc.Budget
+	}
+	result := make(map[string]float64, len(amounts))
+	for bucket, amount := range amounts {
+		result[bucket] = amount.Resolve(capacity, perPoint)
+	}
+	return result
+}
+
+// EstimateScaleConfig converts a team's Linear estimates into normalized
+// points, so that teams using different scales can share capacity and budgets.
+type EstimateScaleConfig struct {
+	// Points maps estimates to normalized points, e.g. {"1": 1, "2": 3, "3": 8}
+	// for an exponential scale.
+	Points map[int]float64 `json:"points"`
+
+	// Factor multiplies estimates not listed in Points; 1 by default.
+	Factor float64 `json:"factor"`
+}
+
+// Normalize converts an estimate into normalized points. A nil scale keeps
+// estimates as they are.
+func (sc *EstimateScaleConfig) Normalize(estimate int) float64 {
+	if sc == nil {
+		return float64(estimate)
+	}
+	if points, ok := sc.Points[estimate]; ok {
+		return points
+	}
+	if sc.Factor != 0 {
+		return float64(estimate) * sc.Factor
+	}
+	return float64(estimate)
+}
+
+// normalizeEstimate converts an estimate of the given team into normalized points.
+func (c *AppConfig) normalizeEstimate(teamKey string, estimate int) float64 {
+	return c.EstimateScales[teamKey].Normalize(estimate)
 }
 
 var config AppConfig
@@ -36,6 +362,100 @@ func loadConfig() {
 
 	log.Printf("config = %s", must(json.MarshalIndent(&config, "", "  ")))
 
+	switch config.InitiativeAttribution.Mode {
+	case "", AttributePrimary, AttributeEven, AttributeWeighted:
+		// ok
+	default:
+		log.Fatalf("config.json: unknown initiative_attribution.mode %q", config.InitiativeAttribution.Mode)
+	}
+
+	switch config.MonthAttribution.Mode {
+	case "", SpreadToTargetMonth, SpreadByWorkingDays:
+		// ok
+	default:
+		log.Fatalf("config.json: unknown month_attribution.mode %q", config.MonthAttribution.Mode)
+	}
+
+	if err := config.validateBudgets(); err != nil {
+		log.Fatalf("config.json: %v", err)
+	}
+
+	for bucket, co := range config.CarryOver {
+		switch co.Mode {
+		case "", CarryNone, CarryForward, CarryPoolQuarter:
+			// ok
+		default:
+			log.Fatalf("config.json: carry_over: %s: unknown mode %q", bucket, co.Mode)
+		}
+	}
+
+	for team, sc := range config.EstimateScales {
+		if sc.Factor < 0 {
+			log.Fatalf("config.json: estimate_scales: %s: negative factor", team)
+		}
+		for estimate, points := range sc.Points {
+			if points < 0 {
+				log.Fatalf("config.json: estimate_scales: %s: negative points for estimate %d", team, estimate)
+			}
+		}
+	}
+
+	if config.Hours.PerPoint < 0 {
+		log.Fatalf("config.json: hours: negative per_point")
+	}
+	for team, f := range config.Hours.Teams {
+		if f <= 0 {
+			log.Fatalf("config.json: hours: teams: %s: per point must be positive", team)
+		}
+	}
+	for name, f := range config.Hours.People {
+		if f <= 0 {
+			log.Fatalf("config.json: hours: people: %s: per point must be positive", name)
+		}
+	}
+
+	for client, cc := range config.Clients {
+		switch cc.Unit {
+		case "", UnitPoints:
+			// ok
+		case UnitHours:
+			if config.Hours.PerPoint <= 0 {
+				log.Fatalf("config.json: clients: %s: retainer in hours requires hours.per_point", client)
+			}
+		default:
+			log.Fatalf("config.json: clients: %s: unknown unit %q", client, cc.Unit)
+		}
+	}
+
+	for factor, weight := range config.Risk.Weights {
+		if !slices.Contains(riskFactors, factor) {
+			log.Fatalf("config.json: risk: unknown factor %q", factor)
+		}
+		if weight < 0 {
+			log.Fatalf("config.json: risk: %s: negative weight", factor)
+		}
+	}
+
+	if config.Dimensions == nil {
+		config.Dimensions = defaultDimensions
+	}
+	if len(config.TagsToBuckets) > 0 && config.Dimension(BucketDimension) == nil {
+		config.Dimensions = append(slices.Clip(config.Dimensions), &DimensionConfig{Name: BucketDimension, Labels: config.TagsToBuckets})
+	}
+	for _, dc := range config.Dimensions {
+		if err := dc.init(); err != nil {
+			log.Fatalf("config.json: dimensions: %v", err)
+		}
+	}
+
+	if err := config.Calendar.init(); err != nil {
+		log.Fatalf("config.json: calendar: %v", err)
+	}
+
+	if err := config.Email.init(); err != nil {
+		log.Fatalf("config.json: email: %v", err)
+	}
+
 	for _, state := range config.StatesToSkip {
 		StatesToSkip[state] = struct{}{}
 	}
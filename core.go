@@ -2,6 +2,7 @@ package main
 
 import (
 	"cmp"
+	"fmt"
 	"maps"
 	"slices"
 	"strings"
@@ -20,16 +21,22 @@ func makeIssue(issue LinearIssue) *IssueData {
 		return nil
 	}
 
+	// Fall back to the milestone's target date if configured
+	dueDateStr := issue.DueDate
+	if (dueDateStr == nil || *dueDateStr == "") && config.MilestoneDueDates && issue.ProjectMilestone != nil {
+		dueDateStr = issue.ProjectMilestone.TargetDate
+	}
+
 	// Compute month info
 	hasCycle := (issue.Cycle != nil)
-	hasDeadline := (issue.DueDate != nil && *issue.DueDate != "")
+	hasDeadline := (dueDateStr != nil && *dueDateStr != "")
 	if !hasCycle && !hasDeadline {
 		return nil
 	}
 
 	var deadline *time.Time
 	if hasDeadline {
-		if d, err := parseDateString(*issue.DueDate); err == nil {
+		if d, err := parseDateString(*dueDateStr); err == nil {
 			deadline = &d
 		}
 	}
@@ -58,8 +65,8 @@ func makeIssue(issue LinearIssue) *IssueData {
 	if issue.Cycle != nil {
 		// Parse cycle end date
 		cycleEnd, err := parseDateString(issue.Cycle.EndsAt)
-		if err == nil && issue.DueDate != nil {
-			dueDate, err := parseDateString(*issue.DueDate)
+		if err == nil && dueDateStr != nil {
+			dueDate, err := parseDateString(*dueDateStr)
 			if err == nil {
 				// Fixed if due date is within 14 days of cycle end
 				if dueDate.Before(cycleEnd.Add(14 * 24 * time.Hour)) {
@@ -71,35 +78,80 @@ func makeIssue(issue LinearIssue) *IssueData {
 		} else {
 			schedule = Planned
 		}
-	} else if issue.DueDate != nil {
+	} else if dueDateStr != nil {
 		schedule = Flex
 	}
 
+	size := config.normalizeEstimate(issue.Team.Key, points)
 	result := &IssueData{
 		Identifier: issue.Identifier,
 		Title:      issue.Title,
-		Points:     points,
+		Points:     size,
+		Size:       size,
+		Estimate:   points,
+		Share:      1,
 		Schedule:   schedule,
+		Priority:   Priority(issue.Priority),
+		SortOrder:  issue.SortOrder,
 		MonthName:  monthName,
 		YearMonth:  yearmonth.FromTime(targetDate),
+		Team:       issue.Team.Name,
+		TeamKey:    issue.Team.Key,
+		State:      issue.State.Name,
 		URL:        issue.URL,
 	}
+	if deadline != nil {
+		result.DueDate = *deadline
+	}
+	if issue.StartedAt != nil {
+		if t, err := parseDateString(*issue.StartedAt); err == nil {
+			result.StartedAt = t
+		}
+	}
+	if cycleStartTime != nil {
+		result.CycleStart, result.CycleEnd = *cycleStartTime, *cycleEndTime
+	}
+	if issue.Project != nil {
+		result.Project = issue.Project.Name
+	}
+	if issue.ProjectMilestone != nil {
+		result.Milestone = issue.ProjectMilestone.Name
+	}
+	if issue.Assignee != nil {
+		result.Assignee = issue.Assignee.Name
+	}
 
 	for _, label := range issue.Labels.Nodes {
 		tag := label.Name
 		result.Labels = append(result.Labels, tag)
-		if s, ok := strings.CutPrefix(tag, "Client-"); ok {
-			result.Clients = append(result.Clients, s)
+
+		var parent string
+		if label.Parent != nil {
+			parent = label.Parent.Name
 		}
-		if s := config.TagsToBuckets[tag]; s != "" {
-			result.Bucket = s
+		for _, dim := range config.Dimensions {
+			if value, ok := dim.Match(tag, parent); ok {
+				if result.Dimensions == nil {
+					result.Dimensions = make(map[string][]string)
+				}
+				result.Dimensions[dim.Name] = append(result.Dimensions[dim.Name], value)
+			}
 		}
 	}
+	result.Clients = result.Dimensions[ClientDimension]
+	if buckets := result.Dimensions[BucketDimension]; len(buckets) > 0 {
+		result.Bucket = buckets[len(buckets)-1]
+	}
 
-	// Compute initiative name
+	// Compute initiative name; attributeIssue picks among multiple initiatives later
+	if issue.Project != nil {
+		for _, init := range issue.Project.Initiatives.Nodes {
+			result.Initiatives = append(result.Initiatives, init.Name)
+		}
+	}
 	result.InitName = "Other"
-	if issue.Project != nil && len(issue.Project.Initiatives.Nodes) > 0 {
-		result.InitName = issue.Project.Initiatives.Nodes[0].Name
+	if len(result.Initiatives) > 0 {
+		result.InitName = result.Initiatives[0]
 	} else if issue.Project != nil && issue.Project.Name != "" {
 		result.InitName = issue.Project.Name
 	}
@@ -110,6 +162,169 @@ func makeIssue(issue LinearIssue) *IssueData {
 	return result
 }
 
+// spreadIssue splits an issue across months according to the month attribution
+// config: issues in a cycle by the fraction of the cycle's working days in each
+// month, and large Flex issues evenly over the working days from now until their
+// due date. Other issues are returned unchanged.
+func spreadIssue(issue *IssueData, mc *MonthAttributionConfig, now time.Time) []*IssueData {
+	var start, end time.Time
+	switch {
+	case mc.Mode != SpreadByWorkingDays:
+		return []*IssueData{issue}
+	case !issue.CycleStart.IsZero():
+		start, end = issue.CycleStart, issue.CycleEnd
+	case issue.Schedule == Flex && mc.SpreadFlexFrom > 0 && issue.Points >= mc.SpreadFlexFrom && issue.DueDate.After(now):
+		start, end = now, issue.DueDate.AddDate(0, 0, 1) // due date is inclusive
+	default:
+		return []*IssueData{issue}
+	}
+
+	days := workingDaysByMonth(start, end)
+	if len(days) < 2 {
+		return []*IssueData{issue}
+	}
+
+	var total int
+	for _, n := range days {
+		total += n
+	}
+	months := slices.Sorted(maps.Keys(days))
+	names := make([]string, len(months))
+	for i, ym := range months {
+		year, month := ym.Components()
+		names[i] = time.Date(year, time.Month(month), 1, 0, 0, 0, 0, time.UTC).Format("January 2006")
+	}
+
+	parts := make([]*IssueData, 0, len(months))
+	for i, ym := range months {
+		fraction := float64(days[ym]) / float64(total)
+		part := *issue
+		part.YearMonth = ym
+		part.MonthName = names[i]
+		part.Share = issue.Share * fraction
+		part.Points = issue.Points * fraction
+		part.SplitAcross = append(slices.Clip(issue.SplitAcross), names...)
+		parts = append(parts, &part)
+	}
+	return parts
+}
+
+// workingDaysByMonth counts the working days from start until end in each month.
+func workingDaysByMonth(start, end time.Time) map[yearmonth.YM]int {
+	result := make(map[yearmonth.YM]int)
+	year, month, day := start.Date()
+	for d := time.Date(year, month, day, 0, 0, 0, 0, time.UTC); d.Before(end); d = d.AddDate(0, 0, 1) {
+		if config.Calendar.IsWorkingDay(d) {
+			result[yearmonth.FromTime(d)]++
+		}
+	}
+	return result
+}
+
+// countWorkingDays counts the working days from start until end.
+func countWorkingDays(start, end time.Time) int {
+	var n int
+	for _, days := range workingDaysByMonth(start, end) {
+		n += days
+	}
+	return n
+}
+
+// attributeIssue splits an issue among the initiatives of its project according
+// to the attribution config. Issues that belong to a bucket or to at most one
+// initiative are returned unchanged.
+func attributeIssue(issue *IssueData, ac *AttributionConfig) []*IssueData {
+	if issue.Bucket != "" || len(issue.Initiatives) < 2 {
+		return []*IssueData{issue}
+	}
+
+	if ac.Mode == AttributeEven || ac.Mode == AttributeWeighted {
+		weights := make([]float64, len(issue.Initiatives))
+		var sum float64
+		for i, name := range issue.Initiatives {
+			w := 1.0
+			if ac.Mode == AttributeWeighted {
+				if v, ok := ac.Weights[name]; ok {
+					w = max(v, 0)
+				}
+			}
+			weights[i] = w
+			sum += w
+		}
+
+		if sum > 0 {
+			return splitIssue(issue, issue.Initiatives, weights)
+		}
+		// all weights are zero, fall back to picking the primary initiative
+	}
+
+	issue.InitName = primaryInitiative(issue.Initiatives, ac.Priority)
+	return []*IssueData{issue}
+}
+
+// pivotIssue regroups an issue by the values of the given dimension, splitting
+// it evenly if it has several values. Issues without a value go to "Other".
+func pivotIssue(issue *IssueData, dimension string) []*IssueData {
+	values := issue.Dimensions[dimension]
+	if len(values) == 0 {
+		issue.InitName = "Other"
+		return []*IssueData{issue}
+	}
+	weights := make([]float64, len(values))
+	for i := range weights {
+		weights[i] = 1
+	}
+	return splitIssue(issue, values, weights)
+}
+
+// splitIssue divides an issue among the given groups proportionally to weights,
+// skipping groups with zero weight. Weights must not all be zero. The groups
+// are added to SplitAcross, keeping the months the issue is already spread across.
+func splitIssue(issue *IssueData, names []string, weights []float64) []*IssueData {
+	var sum float64
+	for _, w := range weights {
+		sum += w
+	}
+
+	parts := make([]*IssueData, 0, len(names))
+	for i, name := range names {
+		if weights[i] == 0 {
+			continue
+		}
+		part := *issue
+		part.InitName = name
+		part.Share = issue.Share * weights[i] / sum
+		part.Points = issue.Points * weights[i] / sum
+		part.SplitAcross = append(slices.Clip(issue.SplitAcross), names...)
+		parts = append(parts, &part)
+	}
+	return parts
+}
+
+// resolvePivot validates a pivot dimension name, returning its canonical spelling.
+func resolvePivot(name string) (string, error) {
+	if name == "" {
+		return "", nil
+	}
+	dim := config.Dimension(name)
+	if dim == nil {
+		return "", fmt.Errorf("unknown dimension %q", name)
+	}
+	return dim.Name, nil
+}
+
+// primaryInitiative returns the initiative that comes first in the priority list,
+// or the first one if none are listed.
+func primaryInitiative(names, priority []string) string {
+	best, bestRank := names[0], len(priority)
+	for _, name := range names {
+		if rank := slices.Index(priority, name); rank >= 0 && rank < bestRank {
+			best, bestRank = name, rank
+		}
+	}
+	return best
+}
+
 // getIssueTargetDate computes the target date for an issue based on its cycle and deadline.
 // The rules are:
 // 1. If it has a cycle and deadline:
@@ -135,24 +350,114 @@ func getIssueTargetDate(cycleStart, cycleMid, cycleEnd, deadline *time.Time) tim
 	return time.Time{}
 }
 
-func computeReport(issues []LinearIssue) (*Report, error) {
+func computeReport(issues []LinearIssue, absences []*Absence, opts ReportOptions) (*Report, error) {
+	now := time.Now().UTC()
+	conv := newConversion(opts.Unit)
+
 	// First convert all issues
-	wrappedIssues := make([]*IssueData, 0, len(issues))
+	var allParts, filteredParts []*IssueData
+	milestones := make(map[string]*MilestoneData)
+	scheduled := make(map[string]*IssueData) // before splitting
 	for _, issue := range issues {
 		if _, ok := StatesToSkip[issue.State.Name]; ok {
 			continue
 		}
-		if wrapped := makeIssue(issue); wrapped != nil {
-			wrappedIssues = append(wrappedIssues, wrapped)
+		wrapped := makeIssue(issue)
+		if wrapped != nil {
+			conv.convertIssue(wrapped)
+		}
+		addToMilestone(milestones, issue, wrapped, conv)
+		if wrapped == nil {
+			continue
+		}
+		scheduled[wrapped.Identifier] = wrapped
+
+		// The spreading threshold is configured in points
+		monthAttribution := config.MonthAttribution
+		monthAttribution.SpreadFlexFrom *= conv.factor(wrapped.TeamKey, wrapped.Assignee)
+		for _, monthPart := range spreadIssue(wrapped, &monthAttribution, now) {
+			for _, part := range attributeIssue(monthPart, &config.InitiativeAttribution) {
+				matches := opts.Filter.Match(part)
+				groups := []*IssueData{part}
+				if opts.Pivot != "" {
+					groups = pivotIssue(part, opts.Pivot)
+				}
+				allParts = append(allParts, groups...)
+				if matches {
+					filteredParts = append(filteredParts, groups...)
+				}
+			}
+		}
+	}
+
+	currentMonth := yearmonth.FromTime(now)
+
+	// Budgets, risks and conflicts depend on all issues, so they are computed
+	// on the unfiltered months and narrowed down to the filter afterwards
+	allMonths := groupMonths(allParts, absences, conv, opts.Pivot, ReportFilter{}, currentMonth)
+
+	// Track budgets across months, starting with the current month since
+	// completed issues of past months are not fetched
+	var ledgers []*BucketLedger
+	if opts.Pivot == "" {
+		ledgers = computeBudgetLedgers(allMonths, currentMonth, absences, conv)
+	}
+	finishMonths(allMonths, opts.Pivot, ReportFilter{}, conv)
+
+	monthSlice := allMonths
+	if !opts.Filter.IsEmpty() {
+		monthSlice = groupMonths(filteredParts, absences, conv, opts.Pivot, opts.Filter, currentMonth)
+		if opts.Filter.Team == "" {
+			copyCarriedIn(monthSlice, allMonths)
 		}
+		finishMonths(monthSlice, opts.Pivot, opts.Filter, conv)
 	}
 
-	currentMonth := yearmonth.FromTime(time.Now().UTC())
+	// Compare milestones with the capacity left until their target date
+	milestoneSlice := slices.Collect(maps.Values(milestones))
+	for _, ms := range milestoneSlice {
+		ms.WeeksLeft = ms.TargetDate.Sub(now).Hours() / 24 / 7
+		ms.CapacityLeft = capacityBetween(now, ms.TargetDate, absences, conv)
+		sortIssues(ms.Issues)
+	}
+	slices.SortFunc(milestoneSlice, func(a, b *MilestoneData) int {
+		if c := a.TargetDate.Compare(b.TargetDate); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.Name, b.Name)
+	})
+
+	deps := buildDependencyGraph(issues, scheduled)
+
+	// Risk thresholds are configured in points
+	risk := config.Risk
+	risk.LargeEstimate = risk.largeEstimate() * conv.perPoint()
+	risk.PersonCapacity = risk.personCapacity() * conv.perPoint()
 
-	// Group by month
+	report := &Report{
+		Months:       monthSlice,
+		Milestones:   milestoneSlice,
+		Budgets:      ledgers,
+		Risks:        assessRisks(allMonths, &risk, now),
+		Dependencies: deps,
+		Conflicts:    deps.findConflicts(),
+		Pivot:        opts.Pivot,
+		Unit:         cmp.Or(opts.Unit, UnitPoints),
+		Filter:       opts.Filter,
+	}
+	if !opts.Filter.IsEmpty() {
+		report.narrow(filteredParts)
+	}
+	return report, nil
+}
+
+// groupMonths adds up issue parts into months. Months list their budgeted
+// buckets and retained clients even if they have no issues, unless the filter
+// excludes them.
+func groupMonths(parts []*IssueData, absences []*Absence, conv conversion, pivot string, filter ReportFilter, currentMonth yearmonth.YM) []*MonthData {
 	monthData := make(map[yearmonth.YM]*MonthData)
 
-	for _, issue := range wrappedIssues {
+	for _, issue := range parts {
 		md, ok := monthData[issue.YearMonth]
 		if !ok {
 			md = &MonthData{
@@ -160,17 +465,42 @@ func computeReport(issues []LinearIssue) (*Report, error) {
 				Key:         issue.YearMonth,
 				IsPast:      issue.YearMonth < currentMonth,
 				Initiatives: make(map[string]*InitiativeData),
+				Clients:     make(map[string]*ClientData),
 			}
 			md.Config = config.ByMonth[md.Key]
 			if md.Config == nil {
 				md.Config = &MonthConfig{}
 			}
-			md.Capacity = md.Config.Capacity
-			if md.Capacity == 0 {
-				md.Capacity = config.DefaultCapacity
+			md.CapacityDerivation = deriveMonthCapacity(md.Key, absences, conv)
+			md.Capacity = md.CapacityDerivation.Total
+			md.Budget = config.monthBudget(md.Key, md.Capacity, conv.perPoint())
+			if filter.Team != "" {
+				// Budgets are shared by all teams, so a team's months
+				// reserve none; capacity from the calendar is per member
+				md.CapacityDerivation = md.CapacityDerivation.forTeam(filter.Team)
+				md.Capacity = md.CapacityDerivation.Total
+				md.Budget = nil
 			}
-			for bucket := range md.Config.Budget {
-				_ = md.LookupInitiative(bucket)
+			monthStart := md.Key.Time()
+			for _, a := range absences {
+				if a.Overlaps(monthStart, monthStart.AddDate(0, 1, 0)) {
+					md.Absences = append(md.Absences, a)
+				}
+			}
+			if pivot == "" {
+				for bucket := range md.Budget {
+					if filter.Initiative == "" || strings.EqualFold(bucket, filter.Initiative) {
+						_ = md.LookupInitiative(bucket)
+					}
+				}
+			}
+			for client, cc := range config.Clients {
+				if filter.Team != "" || filter.Client != "" && !strings.EqualFold(client, filter.Client) {
+					continue
+				}
+				if cc.BudgetFor(md.Key) > 0 {
+					_ = md.LookupClient(client)
+				}
 			}
 			monthData[issue.YearMonth] = md
 		}
@@ -190,6 +520,23 @@ func computeReport(issues []LinearIssue) (*Report, error) {
 			idata.Flex += issue.Points
 		}
 		idata.Total = idata.Fixed + idata.Planned + idata.Flex
+
+		// Add to clients, splitting evenly among them
+		for _, client := range issue.Clients {
+			cdata := md.LookupClient(client)
+			fraction := 1 / float64(len(issue.Clients))
+			points := issue.Points * fraction
+			cdata.addIssue(issue, fraction)
+			switch issue.Schedule {
+			case Fixed:
+				cdata.Fixed += points
+			case Planned:
+				cdata.Planned += points
+			case Flex:
+				cdata.Flex += points
+			}
+			cdata.Used = cdata.Fixed + cdata.Planned + cdata.Flex
+		}
 	}
 
 	// Get sorted slice of months
@@ -199,15 +546,22 @@ func computeReport(issues []LinearIssue) (*Report, error) {
 	slices.SortFunc(monthSlice, func(a, b *MonthData) int {
 		return cmp.Compare(a.Key, b.Key)
 	})
+	return monthSlice
+}
 
-	// Calculate totals and sort initiatives within each month
-	for _, md := range monthSlice {
+// finishMonths calculates the totals of months and sorts their initiatives,
+// issues and clients. Budgets carried over must already be recorded. Client
+// retainers are left out of a team's months, like budgets.
+func finishMonths(months []*MonthData, pivot string, filter ReportFilter, conv conversion) {
+	for _, md := range months {
 		// Calculate month totals from initiatives
 		for _, idata := range md.Initiatives {
-			idata.Budget = md.Config.Budget[idata.Name]
+			if pivot == "" {
+				idata.Budget = md.Budget[idata.Name]
+			}
 
 			idata.Used = idata.Fixed + idata.Planned + idata.Flex
-			idata.Total = max(idata.Budget, idata.Used)
+			idata.Total = max(idata.EffectiveBudget(), idata.Used)
 
 			md.Fixed += idata.Fixed
 			md.Planned += idata.Planned
@@ -221,7 +575,7 @@ func computeReport(issues []LinearIssue) (*Report, error) {
 
 		// Sort initiatives by total points (descending)
 		slices.SortFunc(initSlice, func(a, b *InitiativeData) int {
-			return b.Total - a.Total
+			return cmp.Compare(b.Total, a.Total)
 		})
 
 		// Store sorted initiatives
@@ -231,7 +585,166 @@ func computeReport(issues []LinearIssue) (*Report, error) {
 		for _, idata := range initSlice {
 			idata.sortIssues()
 		}
+
+		md.Ranked = rankIssues(md)
+
+		// Sort clients by used points (descending), then by name
+		for _, cdata := range md.Clients {
+			if filter.Team == "" {
+				cdata.Budget = config.Clients[cdata.Name].retainer(md.Key, conv)
+			}
+			sortIssues(cdata.Issues)
+		}
+		md.SortedClients = slices.SortedFunc(maps.Values(md.Clients), func(a, b *ClientData) int {
+			if c := cmp.Compare(b.Used, a.Used); c != 0 {
+				return c
+			}
+			return cmp.Compare(a.Name, b.Name)
+		})
+	}
+}
+
+// copyCarriedIn copies the budget carried over in the unfiltered months into
+// the initiatives of the filtered ones.
+func copyCarriedIn(months, allMonths []*MonthData) {
+	for _, md := range months {
+		i := slices.IndexFunc(allMonths, func(all *MonthData) bool {
+			return all.Key == md.Key
+		})
+		if i < 0 {
+			continue
+		}
+		for name, idata := range md.Initiatives {
+			if all := allMonths[i].Initiatives[name]; all != nil {
+				idata.CarriedIn = all.CarriedIn
+			}
+		}
+	}
+}
+
+// narrow keeps the budget balances, risks, milestones and conflicts that
+// concern the filtered issue parts. Their numbers still count all issues:
+// a bucket's balance depends on all the work charged to it. Budgets are
+// shared by all teams, so a team's report has no budget balances.
+func (r *Report) narrow(parts []*IssueData) {
+	shown := make(map[string]bool)
+	for _, part := range parts {
+		shown[part.Identifier] = true
 	}
 
-	return &Report{Months: monthSlice}, nil
+	f := r.Filter
+	var ledgers []*BucketLedger
+	for _, bl := range r.Budgets {
+		if f.Team != "" || f.Initiative != "" && !strings.EqualFold(bl.Name, f.Initiative) {
+			continue
+		}
+		var entries []*LedgerEntry
+		for _, le := range bl.Entries {
+			if (f.From == 0 || le.Key >= f.From) && (f.To == 0 || le.Key <= f.To) {
+				entries = append(entries, le)
+			}
+		}
+		if len(entries) > 0 {
+			narrowed := *bl
+			narrowed.Entries = entries
+			ledgers = append(ledgers, &narrowed)
+		}
+	}
+	r.Budgets = ledgers
+
+	r.Risks = slices.DeleteFunc(r.Risks, func(ra *RiskAssessment) bool {
+		return !shown[ra.Issue.Identifier]
+	})
+	r.Milestones = slices.DeleteFunc(r.Milestones, func(ms *MilestoneData) bool {
+		return !slices.ContainsFunc(ms.Issues, func(issue *IssueData) bool {
+			return shown[issue.Identifier]
+		})
+	})
+	r.Conflicts = slices.DeleteFunc(r.Conflicts, func(c *DependencyConflict) bool {
+		return !slices.ContainsFunc(c.Issues, func(id string) bool {
+			return shown[id]
+		})
+	})
+}
+
+// addToMilestone adds an estimated issue to its project milestone, if the
+// milestone has a target date. Unlike months, milestones include issues that
+// are not scheduled yet.
+func addToMilestone(milestones map[string]*MilestoneData, issue LinearIssue, wrapped *IssueData, conv conversion) {
+	ms := issue.ProjectMilestone
+	if ms == nil || ms.TargetDate == nil || issue.Estimate == nil || *issue.Estimate == 0 {
+		return
+	}
+	targetDate, err := parseDateString(*ms.TargetDate)
+	if err != nil {
+		return
+	}
+
+	if wrapped == nil {
+		size := config.normalizeEstimate(issue.Team.Key, *issue.Estimate)
+		wrapped = &IssueData{
+			Identifier: issue.Identifier,
+			Title:      issue.Title,
+			Points:     size,
+			Size:       size,
+			Estimate:   *issue.Estimate,
+			Share:      1,
+			Milestone:  ms.Name,
+			TeamKey:    issue.Team.Key,
+			URL:        issue.URL,
+		}
+		if issue.Assignee != nil {
+			wrapped.Assignee = issue.Assignee.Name
+		}
+		conv.convertIssue(wrapped)
+	}
+
+	mdata := milestones[ms.Id]
+	if mdata == nil {
+		mdata = &MilestoneData{
+			Name:       ms.Name,
+			TargetDate: targetDate,
+		}
+		if issue.Project != nil {
+			mdata.Project = issue.Project.Name
+		}
+		milestones[ms.Id] = mdata
+	}
+	mdata.Points += wrapped.Size
+	mdata.Issues = append(mdata.Issues, wrapped)
+}
+
+// deriveMonthCapacity returns the capacity configured for a month, or derives
+// it from the calendar if one is configured, or falls back to the default.
+// Configured and default capacities are reduced for absences if
+// calendar.points_per_day is set.
+func deriveMonthCapacity(ym yearmonth.YM, absences []*Absence, conv conversion) *CapacityDerivation {
+	if mc := config.ByMonth[ym]; mc != nil && mc.Capacity != 0 {
+		cd := &CapacityDerivation{Source: CapacityConfigured, Total: float64(mc.Capacity)}
+		return conv.convertCapacity(config.Calendar.deductAbsences(cd, ym, absences))
+	}
+	if config.Calendar.IsEnabled() {
+		return conv.convertCapacity(config.Calendar.deriveCalendarCapacity(ym, absences))
+	}
+	cd := &CapacityDerivation{Source: CapacityDefault, Total: float64(config.DefaultCapacity)}
+	return conv.convertCapacity(config.Calendar.deductAbsences(cd, ym, absences))
+}
+
+// capacityBetween estimates the capacity available from start until end,
+// prorating the capacity of each month by the fraction of its days in range.
+func capacityBetween(start, end time.Time, absences []*Absence, conv conversion) float64 {
+	var total float64
+	for start.Before(end) {
+		year, month, _ := start.Date()
+		monthStart := time.Date(year, month, 1, 0, 0, 0, 0, start.Location())
+		monthEnd := monthStart.AddDate(0, 1, 0)
+		rangeEnd := monthEnd
+		if end.Before(rangeEnd) {
+			rangeEnd = end
+		}
+		fraction := rangeEnd.Sub(start).Hours() / monthEnd.Sub(monthStart).Hours()
+		total += deriveMonthCapacity(yearmonth.FromTime(start), absences, conv).Total * fraction
+		start = rangeEnd
+	}
+	return total
 }
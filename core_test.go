@@ -1,8 +1,15 @@
 package main
 
 import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"math"
+	"strings"
 	"testing"
 	"time"
+
+	"github.com/prairiegroupinc/linearsummarybot/yearmonth"
 )
 
 func TestGetIssueTargetDate(t *testing.T) {
@@ -112,3 +119,413 @@ func TestGetIssueTargetDate(t *testing.T) {
 		})
 	}
 }
+
+func TestAttributeIssue(t *testing.T) {
+	newIssue := func() *IssueData {
+		return &IssueData{
+			Identifier:  "DEV-1",
+			Points:      6,
+			Size:        6,
+			Estimate:    6,
+			Share:       1,
+			InitName:    "A",
+			Initiatives: []string{"A", "B", "C"},
+		}
+	}
+
+	tests := []struct {
+		name string
+		ac   AttributionConfig
+		want map[string]float64
+	}{
+		{
+			name: "primary without priority - first initiative",
+			ac:   AttributionConfig{},
+			want: map[string]float64{"A": 6},
+		},
+		{
+			name: "primary with priority - highest ranked initiative",
+			ac:   AttributionConfig{Mode: AttributePrimary, Priority: []string{"X", "C", "B"}},
+			want: map[string]float64{"C": 6},
+		},
+		{
+			name: "even - equal split",
+			ac:   AttributionConfig{Mode: AttributeEven},
+			want: map[string]float64{"A": 2, "B": 2, "C": 2},
+		},
+		{
+			name: "weighted - unlisted weigh 1, zero weight skipped",
+			ac:   AttributionConfig{Mode: AttributeWeighted, Weights: map[string]float64{"A": 2, "C": 0}},
+			want: map[string]float64{"A": 4, "B": 2},
+		},
+		{
+			name: "weighted - all zero falls back to primary",
+			ac:   AttributionConfig{Mode: AttributeWeighted, Weights: map[string]float64{"A": 0, "B": 0, "C": 0}},
+			want: map[string]float64{"A": 6},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parts := attributeIssue(newIssue(), &tt.ac)
+			got := make(map[string]float64)
+			for _, part := range parts {
+				got[part.InitName] += part.Points
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("attributeIssue() = %v, want %v", got, tt.want)
+			}
+			for name, points := range tt.want {
+				if got[name] != points {
+					t.Errorf("attributeIssue() = %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+
+	t.Run("bucket issues are never split", func(t *testing.T) {
+		issue := newIssue()
+		issue.Bucket = "Last Minute"
+		issue.InitName = "Last Minute"
+		parts := attributeIssue(issue, &AttributionConfig{Mode: AttributeEven})
+		if len(parts) != 1 || parts[0].InitName != "Last Minute" || parts[0].Points != 6 {
+			t.Errorf("attributeIssue() split a bucket issue")
+		}
+	})
+}
+
+func TestClientAllocation(t *testing.T) {
+	var issues []LinearIssue
+	err := json.Unmarshal([]byte(`[
+		{"identifier": "DEV-1", "estimate": 4, "dueDate": "2025-07-10", "labels": {"nodes": [{"name": "Client-Acme"}, {"name": "Client-Globex"}]},
+		 "project": {"name": "Checkout", "initiatives": {"nodes": [{"name": "Growth"}, {"name": "Retention"}]}}},
+		{"identifier": "DEV-2", "estimate": 3, "dueDate": "2025-07-20", "labels": {"nodes": [{"name": "Client-Acme"}]}},
+		{"identifier": "DEV-3", "estimate": 1, "dueDate": "2025-07-25", "labels": {"nodes": [{"name": "Client-Acme"}]}, "team": {"key": "ZED"}}
+	]`), &issues)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	saved := config
+	defer func() { config = saved }()
+	config.ByMonth = nil
+	config.Dimensions = defaultDimensions
+	config.InitiativeAttribution = AttributionConfig{Mode: AttributeEven}
+	config.Hours = HoursConfig{PerPoint: 4}
+	config.EstimateScales = map[string]*EstimateScaleConfig{"ZED": {Points: map[int]float64{1: 0}}}
+	config.Clients = map[string]*ClientConfig{
+		"Acme":   {Retainer: 40, Unit: UnitHours},
+		"Globex": {Retainer: 3},
+	}
+
+	tests := []struct {
+		unit Unit
+		want string // client: budget/used, issue=points...
+	}{
+		{UnitPoints, "Acme: 10/5, DEV-2=3 DEV-1=2 (Growth, Retention) DEV-3=0; Globex: 3/2, DEV-1=2 (Growth, Retention)"},
+		{UnitHours, "Acme: 40/20, DEV-2=12 DEV-1=8 (Growth, Retention) DEV-3=0; Globex: 12/8, DEV-1=8 (Growth, Retention)"},
+	}
+	for _, tt := range tests {
+		report, err := computeReport(issues, nil, ReportOptions{Unit: tt.unit})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var clients []string
+		for _, cdata := range report.Months[0].SortedClients {
+			s := fmt.Sprintf("%s: %s/%s,", cdata.Name, formatPoints(cdata.Budget), formatPoints(cdata.Used))
+			for _, issue := range cdata.Issues {
+				s += fmt.Sprintf(" %s=%s", issue.Identifier, formatPoints(issue.Points))
+				if issue.Identifier == "DEV-1" {
+					s += " (" + issue.InitName + ")"
+				}
+			}
+			clients = append(clients, s)
+		}
+		if got := strings.Join(clients, "; "); got != tt.want {
+			t.Errorf("%s: clients = %s, want %s", tt.unit, got, tt.want)
+		}
+		if _, err := json.Marshal(report.Months[0].SortedClients); err != nil {
+			t.Errorf("%s: issues of zero points break JSON: %v", tt.unit, err)
+		}
+	}
+}
+
+func TestTeamReport(t *testing.T) {
+	var issues []LinearIssue
+	err := json.Unmarshal([]byte(`[
+		{"identifier": "DEV-1", "estimate": 5, "dueDate": "2025-09-10", "labels": {"nodes": [{"name": "Client-Acme"}]}, "team": {"key": "DEV"}},
+		{"identifier": "OPS-1", "estimate": 2, "dueDate": "2025-09-05", "labels": {"nodes": [{"name": "Client-Acme"}]}, "team": {"key": "OPS"}}
+	]`), &issues)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	saved := config
+	defer func() { config = saved }()
+	config.ByMonth = map[yearmonth.YM]*MonthConfig{
+		yearmonth.Make(2025, 9): {Capacity: 20, Budget: map[string]BudgetAmount{"Reliability": {Points: 4}}},
+	}
+	config.Clients = map[string]*ClientConfig{"Acme": {Retainer: 10}}
+	config.Dimensions = defaultDimensions
+
+	report, err := computeReport(issues, nil, ReportOptions{Filter: ReportFilter{Team: "ops"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Months) != 1 || report.Months[0].Used != 2 {
+		t.Fatalf("months = %+v, want September with 2 points", report.Months)
+	}
+	// Budgets and retainers are shared by all teams
+	md := report.Months[0]
+	if len(md.Budget) != 0 || len(report.Budgets) != 0 {
+		t.Errorf("team report reserves budgets %v", md.Budget)
+	}
+	if len(md.SortedClients) != 1 || md.SortedClients[0].Budget != 0 || md.SortedClients[0].Used != 2 {
+		t.Errorf("clients = %+v, want Acme with 2 points and no retainer", md.SortedClients)
+	}
+}
+
+func TestDimensionMatch(t *testing.T) {
+	tests := []struct {
+		name      string
+		dim       DimensionConfig
+		label     string
+		parent    string
+		want      string
+		wantMatch bool
+	}{
+		{"prefix match", DimensionConfig{Name: "Area", Prefix: "Area-"}, "Area-Billing", "", "Billing", true},
+		{"prefix mismatch", DimensionConfig{Name: "Area", Prefix: "Area-"}, "Type-Bug", "", "", false},
+		{"regex with group", DimensionConfig{Name: "Type", Regex: `^type:(\w+)$`}, "type:bug", "", "bug", true},
+		{"regex without group", DimensionConfig{Name: "Type", Regex: `^(?:Bug|Feature)$`}, "Feature", "", "Feature", true},
+		{"regex mismatch", DimensionConfig{Name: "Type", Regex: `^(Bug|Feature)$`}, "Chore", "", "", false},
+		{"parent match", DimensionConfig{Name: "Platform", Parent: "Platform"}, "iOS", "Platform", "iOS", true},
+		{"parent mismatch", DimensionConfig{Name: "Platform", Parent: "Platform"}, "iOS", "", "", false},
+		{"labels match", DimensionConfig{Name: "Bucket", Labels: map[string]string{"LastMinute": "Last Minute"}}, "LastMinute", "", "Last Minute", true},
+		{"labels mismatch", DimensionConfig{Name: "Bucket", Labels: map[string]string{"LastMinute": "Last Minute"}}, "Last Minute", "", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.dim.init(); err != nil {
+				t.Fatalf("init() failed: %v", err)
+			}
+			got, ok := tt.dim.Match(tt.label, tt.parent)
+			if got != tt.want || ok != tt.wantMatch {
+				t.Errorf("Match(%q, %q) = %q, %v, want %q, %v", tt.label, tt.parent, got, ok, tt.want, tt.wantMatch)
+			}
+		})
+	}
+}
+
+func TestCapacityBetween(t *testing.T) {
+	saved := config
+	defer func() { config = saved }()
+	config.DefaultCapacity = 100
+	config.ByMonth = map[yearmonth.YM]*MonthConfig{
+		yearmonth.Make(2025, 7): {Capacity: 62},
+	}
+
+	date := func(year, month, day int) time.Time {
+		return time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		name       string
+		start, end time.Time
+		want       float64
+	}{
+		{"whole month", date(2025, 7, 1), date(2025, 8, 1), 62},
+		{"part of a month", date(2025, 7, 1), date(2025, 7, 11), 20},
+		{"across months", date(2025, 7, 22), date(2025, 8, 1).AddDate(0, 1, 0), 20 + 100},
+		{"end before start", date(2025, 7, 22), date(2025, 7, 1), 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := capacityBetween(tt.start, tt.end, nil, conversion{})
+			if math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("capacityBetween() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSpreadIssue(t *testing.T) {
+	date := func(year, month, day int) time.Time {
+		return time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+	}
+	spread := &MonthAttributionConfig{Mode: SpreadByWorkingDays, SpreadFlexFrom: 8}
+	now := date(2025, 1, 29) // Wednesday
+
+	tests := []struct {
+		name  string
+		mc    *MonthAttributionConfig
+		issue IssueData
+		want  map[yearmonth.YM]float64
+	}{
+		{
+			name:  "target mode - unchanged",
+			mc:    &MonthAttributionConfig{},
+			issue: IssueData{Points: 13, Share: 1, Schedule: Planned, YearMonth: yearmonth.Make(2025, 2), CycleStart: date(2025, 1, 27), CycleEnd: date(2025, 2, 10)},
+			want:  map[yearmonth.YM]float64{yearmonth.Make(2025, 2): 13},
+		},
+		{
+			name:  "cycle across months - split by working days",
+			mc:    spread,
+			issue: IssueData{Points: 13, Share: 1, Schedule: Planned, YearMonth: yearmonth.Make(2025, 2), CycleStart: date(2025, 1, 27), CycleEnd: date(2025, 2, 10)},
+			want:  map[yearmonth.YM]float64{yearmonth.Make(2025, 1): 6.5, yearmonth.Make(2025, 2): 6.5},
+		},
+		{
+			name:  "cycle within a month - unchanged",
+			mc:    spread,
+			issue: IssueData{Points: 5, Share: 1, Schedule: Planned, YearMonth: yearmonth.Make(2025, 2), CycleStart: date(2025, 2, 3), CycleEnd: date(2025, 2, 17)},
+			want:  map[yearmonth.YM]float64{yearmonth.Make(2025, 2): 5},
+		},
+		{
+			name:  "large flex issue - spread from now until due date",
+			mc:    spread,
+			issue: IssueData{Points: 8, Share: 1, Schedule: Flex, YearMonth: yearmonth.Make(2025, 2), DueDate: date(2025, 2, 4)},
+			want:  map[yearmonth.YM]float64{yearmonth.Make(2025, 1): 4.8, yearmonth.Make(2025, 2): 3.2},
+		},
+		{
+			name:  "small flex issue - unchanged",
+			mc:    spread,
+			issue: IssueData{Points: 5, Share: 1, Schedule: Flex, YearMonth: yearmonth.Make(2025, 2), DueDate: date(2025, 2, 4)},
+			want:  map[yearmonth.YM]float64{yearmonth.Make(2025, 2): 5},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parts := spreadIssue(&tt.issue, tt.mc, now)
+			got := make(map[yearmonth.YM]float64)
+			for _, part := range parts {
+				got[part.YearMonth] += part.Points
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("spreadIssue() = %v, want %v", got, tt.want)
+			}
+			for ym, points := range tt.want {
+				if math.Abs(got[ym]-points) > 1e-9 {
+					t.Errorf("spreadIssue() = %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+
+	t.Run("spread then split among initiatives", func(t *testing.T) {
+		issue := IssueData{Points: 12, Share: 1, Schedule: Planned, YearMonth: yearmonth.Make(2025, 2), CycleStart: date(2025, 1, 27), CycleEnd: date(2025, 2, 10), Initiatives: []string{"A", "B"}}
+		var parts []*IssueData
+		for _, monthPart := range spreadIssue(&issue, spread, now) {
+			parts = append(parts, attributeIssue(monthPart, &AttributionConfig{Mode: AttributeEven})...)
+		}
+		if len(parts) != 4 {
+			t.Fatalf("got %d parts, want 4", len(parts))
+		}
+		for _, part := range parts {
+			if got, want := strings.Join(part.SplitAcross, ", "), "January 2025, February 2025, A, B"; got != want || part.Share != 0.25 {
+				t.Errorf("%s %s: SplitAcross = %s, Share = %v, want %s, 0.25", part.MonthName, part.InitName, got, part.Share, want)
+			}
+		}
+	})
+}
+
+func TestMonthBudget(t *testing.T) {
+	var c AppConfig
+	err := json.Unmarshal([]byte(`{
+		"default_budget": {"Reliability": "25%", "Last Minute": 20},
+		"months": {
+			"2025-07": {"capacity": 100, "budget": {"Reliability": "10%"}},
+			"2025-08": {"capacity": 120}
+		}
+	}`), &c)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		ym       yearmonth.YM
+		capacity float64
+		want     map[string]float64
+	}{
+		{yearmonth.Make(2025, 7), 100, map[string]float64{"Reliability": 10}},
+		{yearmonth.Make(2025, 8), 120, map[string]float64{"Reliability": 30, "Last Minute": 20}},
+		{yearmonth.Make(2025, 9), 160, map[string]float64{"Reliability": 40, "Last Minute": 20}},
+	}
+	for _, tt := range tests {
+		got := c.monthBudget(tt.ym, tt.capacity, 1)
+		if !maps.Equal(got, tt.want) {
+			t.Errorf("monthBudget(%v) = %v, want %v", tt.ym, got, tt.want)
+		}
+	}
+
+	for _, data := range []string{`"25"`, `"-10%"`, `-5`} {
+		var b BudgetAmount
+		if err := json.Unmarshal([]byte(data), &b); err == nil {
+			t.Errorf("budget %s parsed as %+v, want error", data, b)
+		}
+	}
+
+	for _, tt := range []struct {
+		config string
+		valid  bool
+	}{
+		{`{"default_budget": {"A": "60%", "B": "40%"}}`, true},
+		{`{"default_budget": {"A": "60%", "B": "50%"}}`, false},
+		{`{"months": {"2025-07": {"capacity": 100, "budget": {"A": "50%", "B": 50}}}}`, true},
+		{`{"months": {"2025-07": {"capacity": 100, "budget": {"A": "50%", "B": 60}}}}`, false},
+		{`{"default_budget": {"A": 80}, "months": {"2025-07": {"capacity": 60}}}`, false},
+		{`{"default_budget": {"A": 80}, "months": {"2025-07": {"budget": {}}}}`, true},
+	} {
+		var c AppConfig
+		if err := json.Unmarshal([]byte(tt.config), &c); err != nil {
+			t.Fatal(err)
+		}
+		if err := c.validateBudgets(); (err == nil) != tt.valid {
+			t.Errorf("validateBudgets(%s) = %v, want valid %v", tt.config, err, tt.valid)
+		}
+	}
+}
+
+func TestNormalizeEstimate(t *testing.T) {
+	saved := config
+	defer func() { config = saved }()
+	config = AppConfig{}
+	err := json.Unmarshal([]byte(`{
+		"estimate_scales": {
+			"EXP": {"points": {"1": 1, "2": 3, "3": 8}},
+			"HRS": {"factor": 0.25, "points": {"1": 0.5}}
+		}
+	}`), &config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		team     string
+		estimate int
+		want     float64
+	}{
+		{"DEV", 5, 5},   // no scale
+		{"EXP", 2, 3},   // mapped
+		{"EXP", 5, 5},   // unmapped, factor defaults to 1
+		{"HRS", 1, 0.5}, // mapped takes precedence over factor
+		{"HRS", 16, 4},
+	}
+	for _, tt := range tests {
+		if got := config.normalizeEstimate(tt.team, tt.estimate); got != tt.want {
+			t.Errorf("normalizeEstimate(%s, %d) = %v, want %v", tt.team, tt.estimate, got, tt.want)
+		}
+	}
+
+	var issue LinearIssue
+	err = json.Unmarshal([]byte(`{"identifier": "EXP-1", "estimate": 3, "dueDate": "2025-07-15", "team": {"key": "EXP"}}`), &issue)
+	if err != nil {
+		t.Fatal(err)
+	}
+	idata := makeIssue(issue)
+	if idata.Estimate != 3 || idata.Size != 8 || idata.Points != 8 {
+		t.Errorf("makeIssue() estimate = %d, size = %v, points = %v, want 3, 8, 8", idata.Estimate, idata.Size, idata.Points)
+	}
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"cmp"
+	"slices"
+)
+
+// RankedIssue is an issue in a month's ranking against the capacity line.
+type RankedIssue struct {
+	Issue      *IssueData
+	Points     float64 // points in this month, summed over initiatives if split
+	Cumulative float64 // points of this and all higher-ranked issues
+	Fits       bool    // whether Cumulative is within the capacity for issues
+}
+
+// IssueCapacity returns the capacity left for issues after reserving the
+// unused part of budgets.
+func (md *MonthData) IssueCapacity() float64 {
+	return md.Capacity - (md.Total - md.Used)
+}
+
+// CutlineIndex returns the index of the first issue below the capacity line,
+// or len(Ranked) if everything fits.
+func (md *MonthData) CutlineIndex() int {
+	for i, r := range md.Ranked {
+		if !r.Fits {
+			return i
+		}
+	}
+	return len(md.Ranked)
+}
+
+// AboveCutline returns the issues that fit within capacity.
+func (md *MonthData) AboveCutline() []*RankedIssue {
+	return md.Ranked[:md.CutlineIndex()]
+}
+
+// BelowCutline returns the issues at risk.
+func (md *MonthData) BelowCutline() []*RankedIssue {
+	return md.Ranked[md.CutlineIndex():]
+}
+
+// rankIssues ranks a month's issues: Fixed first, then by priority, with
+// Planned before Flex and Linear's manual order breaking ties. It then marks
+// which issues fit within the month's capacity.
+func rankIssues(md *MonthData) []*RankedIssue {
+	byID := make(map[string]*RankedIssue)
+	var ranked []*RankedIssue
+	for _, idata := range md.SortedInitiatives {
+		for _, issue := range idata.Issues {
+			if r := byID[issue.Identifier]; r != nil {
+				r.Points += issue.Points
+				continue
+			}
+			r := &RankedIssue{Issue: issue, Points: issue.Points}
+			byID[issue.Identifier] = r
+			ranked = append(ranked, r)
+		}
+	}
+
+	fixedFirst := func(s Schedule) int {
+		if s == Fixed {
+			return 0
+		}
+		return 1
+	}
+	slices.SortFunc(ranked, func(a, b *RankedIssue) int {
+		return cmp.Or(
+			cmp.Compare(fixedFirst(a.Issue.Schedule), fixedFirst(b.Issue.Schedule)),
+			cmp.Compare(a.Issue.Priority.rank(), b.Issue.Priority.rank()),
+			cmp.Compare(a.Issue.Schedule, b.Issue.Schedule),
+			cmp.Compare(a.Issue.SortOrder, b.Issue.SortOrder),
+			cmp.Compare(a.Issue.Identifier, b.Issue.Identifier),
+		)
+	})
+
+	capacity := md.IssueCapacity()
+	var cumulative float64
+	for _, r := range ranked {
+		cumulative += r.Points
+		r.Cumulative = cumulative
+		r.Fits = cumulative <= capacity
+	}
+	return ranked
+}
@@ -0,0 +1,47 @@
+package main
+
+import "testing"
+
+func TestRankIssues(t *testing.T) {
+	growth := &InitiativeData{Name: "Growth", Issues: []*IssueData{
+		{Identifier: "DEV-1", Points: 5, Schedule: Flex, Priority: Urgent},
+		{Identifier: "DEV-2", Points: 3, Schedule: Planned, Priority: NoPriority},
+		{Identifier: "DEV-3", Points: 4, Schedule: Fixed, Priority: Low},
+		{Identifier: "DEV-4", Points: 1, Schedule: Planned, Priority: Urgent, SortOrder: 2},
+	}}
+	infra := &InitiativeData{Name: "Infra", Issues: []*IssueData{
+		{Identifier: "DEV-4", Points: 1, Schedule: Planned, Priority: Urgent, SortOrder: 2}, // other half of a split issue
+		{Identifier: "DEV-5", Points: 2, Schedule: Planned, Priority: Urgent, SortOrder: 1},
+	}}
+	md := &MonthData{
+		Capacity:          14,
+		Used:              16,
+		Total:             18, // 2 points of unused budget
+		SortedInitiatives: []*InitiativeData{growth, infra},
+	}
+
+	md.Ranked = rankIssues(md)
+
+	want := []struct {
+		id     string
+		points float64
+		fits   bool
+	}{
+		{"DEV-3", 4, true},
+		{"DEV-5", 2, true},
+		{"DEV-4", 2, true},
+		{"DEV-1", 5, false},
+		{"DEV-2", 3, false},
+	}
+	if len(md.Ranked) != len(want) {
+		t.Fatalf("got %d ranked issues, want %d", len(md.Ranked), len(want))
+	}
+	for i, r := range md.Ranked {
+		if r.Issue.Identifier != want[i].id || r.Points != want[i].points || r.Fits != want[i].fits {
+			t.Errorf("rank %d = %s %v fits=%v, want %s %v fits=%v", i, r.Issue.Identifier, r.Points, r.Fits, want[i].id, want[i].points, want[i].fits)
+		}
+	}
+	if md.CutlineIndex() != 3 {
+		t.Errorf("CutlineIndex() = %d, want 3", md.CutlineIndex())
+	}
+}
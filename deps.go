@@ -0,0 +1,271 @@
+package main
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// DependencyGraph holds the blocking relations between issues.
+type DependencyGraph struct {
+	Nodes map[string]*DependencyNode // by identifier
+}
+
+// DependencyNode is an issue that blocks or is blocked by other issues.
+type DependencyNode struct {
+	Identifier string
+	Title      string
+	URL        string
+	Issue      *IssueData // nil if the issue is not scheduled
+	State      string     // Linear workflow state
+	Resolved   bool       // completed, canceled or in states_to_skip, so it no longer blocks anything
+	Estimated  bool
+	BlockedBy  []string
+	Blocks     []string
+}
+
+// IsScheduled returns whether the issue is part of the report.
+func (n *DependencyNode) IsScheduled() bool {
+	return n.Issue != nil
+}
+
+// Status describes where the issue is scheduled, e.g. "Flex, March 2025".
+func (n *DependencyNode) Status() string {
+	switch {
+	case n.Resolved && isSkippedState(n.State):
+		return n.State
+	case n.Resolved:
+		return "done"
+	case n.Issue == nil && !n.Estimated:
+		return "unestimated"
+	case n.Issue == nil:
+		return "unscheduled"
+	default:
+		return n.Issue.Schedule.String() + ", " + n.Issue.MonthName
+	}
+}
+
+// ConflictKind is the kind of a dependency conflict.
+type ConflictKind string
+
+const (
+	// ConflictOrder is an issue scheduled in an earlier month than its blocker.
+	ConflictOrder ConflictKind = "order"
+
+	// ConflictFixedBlocked is a Fixed issue blocked by a Flex, unscheduled or
+	// unestimated issue.
+	ConflictFixedBlocked ConflictKind = "fixed_blocked"
+
+	// ConflictCycle is a set of issues that block each other.
+	ConflictCycle ConflictKind = "cycle"
+)
+
+// DependencyConflict is a scheduling problem caused by blocking relations.
+type DependencyConflict struct {
+	Kind        ConflictKind
+	Issues      []string // the blocked issue and its blocker, or the issues of a cycle
+	Description string
+}
+
+// Involves returns whether the conflict concerns the given issue.
+func (c *DependencyConflict) Involves(identifier string) bool {
+	return slices.Contains(c.Issues, identifier)
+}
+
+// ConflictsOf returns the dependency conflicts involving the given issue.
+func (r *Report) ConflictsOf(identifier string) []*DependencyConflict {
+	var result []*DependencyConflict
+	for _, c := range r.Conflicts {
+		if c.Involves(identifier) {
+			result = append(result, c)
+		}
+	}
+	return result
+}
+
+// buildDependencyGraph collects "blocks" relations of the fetched issues.
+// Scheduled maps identifiers to issues before splitting; related issues that
+// were not fetched are added from the relation itself.
+func buildDependencyGraph(issues []LinearIssue, scheduled map[string]*IssueData) *DependencyGraph {
+	g := &DependencyGraph{Nodes: make(map[string]*DependencyNode)}
+	for _, issue := range issues {
+		self := LinearRelated{Identifier: issue.Identifier, Title: issue.Title, URL: issue.URL, Estimate: issue.Estimate}
+		self.State.Name = issue.State.Name // fetched issues are neither completed nor canceled
+		for _, rel := range issue.Relations.Nodes {
+			if rel.Type == "blocks" {
+				g.addEdge(g.node(self), g.node(rel.RelatedIssue))
+			}
+		}
+		for _, rel := range issue.InverseRelations.Nodes {
+			if rel.Type == "blocks" {
+				g.addEdge(g.node(rel.Issue), g.node(self))
+			}
+		}
+	}
+	for id, n := range g.Nodes {
+		n.Issue = scheduled[id]
+		slices.Sort(n.BlockedBy)
+		slices.Sort(n.Blocks)
+	}
+	return g
+}
+
+func (g *DependencyGraph) node(issue LinearRelated) *DependencyNode {
+	n := g.Nodes[issue.Identifier]
+	if n == nil {
+		n = &DependencyNode{
+			Identifier: issue.Identifier,
+			Title:      issue.Title,
+			URL:        issue.URL,
+			State:      issue.State.Name,
+			Resolved:   issue.State.Type == "completed" || issue.State.Type == "canceled" || isSkippedState(issue.State.Name),
+			Estimated:  issue.Estimate != nil && *issue.Estimate != 0,
+		}
+		g.Nodes[issue.Identifier] = n
+	}
+	return n
+}
+
+// isSkippedState returns whether issues in the state are left out of the
+// report, e.g. because they are in QA.
+func isSkippedState(state string) bool {
+	_, ok := StatesToSkip[state]
+	return ok
+}
+
+func (g *DependencyGraph) addEdge(blocker, blocked *DependencyNode) {
+	if !slices.Contains(blocker.Blocks, blocked.Identifier) {
+		blocker.Blocks = append(blocker.Blocks, blocked.Identifier)
+		blocked.BlockedBy = append(blocked.BlockedBy, blocker.Identifier)
+	}
+}
+
+// Node returns the node of an issue, or nil if it has no blocking relations.
+func (g *DependencyGraph) Node(identifier string) *DependencyNode {
+	if g == nil {
+		return nil
+	}
+	return g.Nodes[identifier]
+}
+
+func (g *DependencyGraph) lookupAll(identifiers []string) []*DependencyNode {
+	result := make([]*DependencyNode, 0, len(identifiers))
+	for _, id := range identifiers {
+		result = append(result, g.Nodes[id])
+	}
+	return result
+}
+
+// findConflicts reports issues scheduled in an earlier month than their
+// blockers, Fixed issues blocked by Flex, unscheduled or unestimated issues,
+// and cycles. Resolved issues, including those in states_to_skip, are ignored. Months are compared by target month, before
+// spreading across months.
+func (g *DependencyGraph) findConflicts() []*DependencyConflict {
+	var result []*DependencyConflict
+	ids := slices.Sorted(maps.Keys(g.Nodes))
+	for _, id := range ids {
+		n := g.Nodes[id]
+		if n.Resolved || n.Issue == nil {
+			continue
+		}
+		for _, b := range g.lookupAll(n.BlockedBy) {
+			if b.Resolved {
+				continue
+			}
+			if b.Issue != nil && n.Issue.YearMonth < b.Issue.YearMonth {
+				result = append(result, &DependencyConflict{
+					Kind:        ConflictOrder,
+					Issues:      []string{n.Identifier, b.Identifier},
+					Description: fmt.Sprintf("%s (%s) is scheduled before its blocker %s (%s)", n.Identifier, n.Issue.MonthName, b.Identifier, b.Issue.MonthName),
+				})
+			}
+			if n.Issue.Schedule == Fixed && (b.Issue == nil || b.Issue.Schedule == Flex) {
+				kind := b.Status()
+				if b.Issue != nil {
+					kind = "Flex"
+				}
+				result = append(result, &DependencyConflict{
+					Kind:        ConflictFixedBlocked,
+					Issues:      []string{n.Identifier, b.Identifier},
+					Description: fmt.Sprintf("Fixed %s is blocked by %s %s", n.Identifier, kind, b.Identifier),
+				})
+			}
+		}
+	}
+
+	for _, cycle := range g.cycles() {
+		var desc string
+		if len(cycle) == 1 {
+			desc = cycle[0] + " blocks itself"
+		} else {
+			desc = strings.Join(cycle[:len(cycle)-1], ", ") + " and " + cycle[len(cycle)-1] + " block each other"
+		}
+		result = append(result, &DependencyConflict{
+			Kind:        ConflictCycle,
+			Issues:      cycle,
+			Description: desc,
+		})
+	}
+	return result
+}
+
+// cycles returns the sets of unresolved issues that block each other, i.e.
+// the strongly connected components of the graph with a cycle, each sorted
+// by identifier.
+func (g *DependencyGraph) cycles() [][]string {
+	// Tarjan's algorithm
+	var (
+		index   = make(map[string]int)
+		lowlink = make(map[string]int)
+		onStack = make(map[string]bool)
+		stack   []string
+		result  [][]string
+	)
+	var visit func(id string)
+	visit = func(id string) {
+		index[id] = len(index)
+		lowlink[id] = index[id]
+		stack = append(stack, id)
+		onStack[id] = true
+
+		for _, next := range g.Nodes[id].Blocks {
+			if g.Nodes[next].Resolved {
+				continue
+			}
+			if _, seen := index[next]; !seen {
+				visit(next)
+				lowlink[id] = min(lowlink[id], lowlink[next])
+			} else if onStack[next] {
+				lowlink[id] = min(lowlink[id], index[next])
+			}
+		}
+
+		if lowlink[id] == index[id] {
+			var component []string
+			for {
+				top := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				onStack[top] = false
+				component = append(component, top)
+				if top == id {
+					break
+				}
+			}
+			if len(component) > 1 || slices.Contains(g.Nodes[id].Blocks, id) {
+				slices.Sort(component)
+				result = append(result, component)
+			}
+		}
+	}
+
+	for _, id := range slices.Sorted(maps.Keys(g.Nodes)) {
+		if _, seen := index[id]; !seen && !g.Nodes[id].Resolved {
+			visit(id)
+		}
+	}
+	slices.SortFunc(result, func(a, b []string) int {
+		return strings.Compare(a[0], b[0])
+	})
+	return result
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+
+	"github.com/prairiegroupinc/linearsummarybot/yearmonth"
+)
+
+func TestFindConflicts(t *testing.T) {
+	// DEV-1 blocks DEV-2 and DEV-3; DEV-4 (unscheduled), DEV-9 (done), DEV-10
+	// (in QA) and DEV-11 (unestimated) block DEV-3; DEV-5, DEV-6 and DEV-7 form
+	// a cycle. DEV-20 (in QA) and DEV-22 (unscheduled) are fetched before
+	// DEV-21, which they block.
+	var issues []LinearIssue
+	err := json.Unmarshal([]byte(`[
+		{"identifier": "DEV-20", "estimate": 3, "state": {"name": "In QA"}, "relations": {"nodes": [
+			{"type": "blocks", "relatedIssue": {"identifier": "DEV-21"}}
+		]}},
+		{"identifier": "DEV-22", "estimate": 2, "state": {"name": "Todo"}, "relations": {"nodes": [
+			{"type": "blocks", "relatedIssue": {"identifier": "DEV-21"}}
+		]}},
+		{"identifier": "DEV-21", "inverseRelations": {"nodes": [
+			{"type": "blocks", "issue": {"identifier": "DEV-20", "estimate": 3, "state": {"name": "In QA", "type": "started"}}},
+			{"type": "blocks", "issue": {"identifier": "DEV-22", "estimate": 2, "state": {"name": "Todo", "type": "unstarted"}}}
+		]}},
+		{"identifier": "DEV-1", "relations": {"nodes": [
+			{"type": "blocks", "relatedIssue": {"identifier": "DEV-2"}},
+			{"type": "related", "relatedIssue": {"identifier": "DEV-8"}}
+		]}},
+		{"identifier": "DEV-2", "inverseRelations": {"nodes": [
+			{"type": "blocks", "issue": {"identifier": "DEV-1"}}
+		]}},
+		{"identifier": "DEV-3", "inverseRelations": {"nodes": [
+			{"type": "blocks", "issue": {"identifier": "DEV-1"}},
+			{"type": "blocks", "issue": {"identifier": "DEV-4", "estimate": 3}},
+			{"type": "blocks", "issue": {"identifier": "DEV-9", "state": {"type": "completed"}}},
+			{"type": "blocks", "issue": {"identifier": "DEV-10", "estimate": 2, "state": {"name": "In QA", "type": "started"}}},
+			{"type": "blocks", "issue": {"identifier": "DEV-11", "state": {"name": "Todo", "type": "unstarted"}}}
+		]}},
+		{"identifier": "DEV-5", "relations": {"nodes": [{"type": "blocks", "relatedIssue": {"identifier": "DEV-6"}}]}},
+		{"identifier": "DEV-6", "relations": {"nodes": [{"type": "blocks", "relatedIssue": {"identifier": "DEV-7"}}]}},
+		{"identifier": "DEV-7", "relations": {"nodes": [{"type": "blocks", "relatedIssue": {"identifier": "DEV-5"}}]}}
+	]`), &issues)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	june, july := yearmonth.Make(2025, 6), yearmonth.Make(2025, 7)
+	scheduled := map[string]*IssueData{
+		"DEV-1":  {Identifier: "DEV-1", Schedule: Flex, YearMonth: july, MonthName: "July 2025"},
+		"DEV-2":  {Identifier: "DEV-2", Schedule: Planned, YearMonth: june, MonthName: "June 2025"},
+		"DEV-3":  {Identifier: "DEV-3", Schedule: Fixed, YearMonth: july, MonthName: "July 2025"},
+		"DEV-5":  {Identifier: "DEV-5", Schedule: Planned, YearMonth: july, MonthName: "July 2025"},
+		"DEV-6":  {Identifier: "DEV-6", Schedule: Planned, YearMonth: july, MonthName: "July 2025"},
+		"DEV-7":  {Identifier: "DEV-7", Schedule: Planned, YearMonth: july, MonthName: "July 2025"},
+		"DEV-21": {Identifier: "DEV-21", Schedule: Fixed, YearMonth: july, MonthName: "July 2025"},
+	}
+
+	saved := StatesToSkip
+	defer func() { StatesToSkip = saved }()
+	StatesToSkip = map[string]struct{}{"In QA": {}}
+
+	g := buildDependencyGraph(issues, scheduled)
+	if n := g.Node("DEV-1"); !slices.Equal(n.Blocks, []string{"DEV-2", "DEV-3"}) {
+		t.Errorf("DEV-1 blocks %v, want [DEV-2 DEV-3]", n.Blocks)
+	}
+	if n := g.Node("DEV-3"); !slices.Equal(n.BlockedBy, []string{"DEV-1", "DEV-10", "DEV-11", "DEV-4", "DEV-9"}) {
+		t.Errorf("DEV-3 blocked by %v, want [DEV-1 DEV-10 DEV-11 DEV-4 DEV-9]", n.BlockedBy)
+	}
+	for _, id := range []string{"DEV-10", "DEV-20"} {
+		if got := g.Node(id).Status(); got != "In QA" {
+			t.Errorf("%s status = %q, want In QA", id, got)
+		}
+	}
+	if g.Node("DEV-8") != nil {
+		t.Errorf("DEV-8 has only a non-blocking relation, should not be in the graph")
+	}
+
+	want := []struct {
+		kind ConflictKind
+		desc string
+	}{
+		{ConflictOrder, "DEV-2 (June 2025) is scheduled before its blocker DEV-1 (July 2025)"},
+		{ConflictFixedBlocked, "Fixed DEV-21 is blocked by unscheduled DEV-22"},
+		{ConflictFixedBlocked, "Fixed DEV-3 is blocked by Flex DEV-1"},
+		{ConflictFixedBlocked, "Fixed DEV-3 is blocked by unestimated DEV-11"},
+		{ConflictFixedBlocked, "Fixed DEV-3 is blocked by unscheduled DEV-4"},
+		{ConflictCycle, "DEV-5, DEV-6 and DEV-7 block each other"},
+	}
+	conflicts := g.findConflicts()
+	if len(conflicts) != len(want) {
+		for _, c := range conflicts {
+			t.Log(c.Description)
+		}
+		t.Fatalf("got %d conflicts, want %d", len(conflicts), len(want))
+	}
+	for i, c := range conflicts {
+		if c.Kind != want[i].kind || c.Description != want[i].desc {
+			t.Errorf("conflict %d = %s %q, want %s %q", i, c.Kind, c.Description, want[i].kind, want[i].desc)
+		}
+	}
+}
@@ -0,0 +1,201 @@
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/prairiegroupinc/linearsummarybot/yearmonth"
+)
+
+// DrillDownPageData is a month, or an initiative of a month, with its issues
+// grouped by project.
+type DrillDownPageData struct {
+	Report     *Report
+	Month      *MonthData
+	Initiative *InitiativeData // nil for the whole month
+	Projects   []*ProjectGroup
+	Sort       IssueSort
+	Total      float64
+}
+
+// ProjectGroup holds the issues of a project, "No project" for issues
+// without one.
+type ProjectGroup struct {
+	Name   string
+	Points float64
+	Issues []*IssueData
+}
+
+// IssueSort orders the issue tables of drill-down pages by a column.
+type IssueSort struct {
+	Column string // empty for the order of the report
+	Desc   bool
+}
+
+// issueColumns compare issues by each sortable column, ascending.
+var issueColumns = map[string]func(a, b *IssueData) int{
+	"points": func(a, b *IssueData) int {
+		return cmp.Compare(a.Points, b.Points)
+	},
+	"schedule": func(a, b *IssueData) int {
+		return cmp.Compare(a.Schedule, b.Schedule)
+	},
+	"due": func(a, b *IssueData) int {
+		return compareDates(a.DueDate, b.DueDate)
+	},
+	"cycle": func(a, b *IssueData) int {
+		return compareDates(a.CycleStart, b.CycleStart)
+	},
+	"assignee": func(a, b *IssueData) int {
+		return compareStrings(a.Assignee, b.Assignee)
+	},
+	"state": func(a, b *IssueData) int {
+		return compareStrings(a.State, b.State)
+	},
+}
+
+// compareDates orders zero dates last.
+func compareDates(a, b time.Time) int {
+	if a.IsZero() || b.IsZero() {
+		return cmp.Compare(boolInt(a.IsZero()), boolInt(b.IsZero()))
+	}
+	return a.Compare(b)
+}
+
+// compareStrings orders strings case-insensitively, empty ones last.
+func compareStrings(a, b string) int {
+	if a == "" || b == "" {
+		return cmp.Compare(boolInt(a == ""), boolInt(b == ""))
+	}
+	return cmp.Compare(strings.ToLower(a), strings.ToLower(b))
+}
+
+func boolInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// parseIssueSort parses ?sort=points, or ?sort=-points for descending order.
+func parseIssueSort(s string) (IssueSort, error) {
+	if s == "" {
+		return IssueSort{}, nil
+	}
+	column, desc := strings.CutPrefix(s, "-")
+	if _, ok := issueColumns[column]; !ok {
+		return IssueSort{}, fmt.Errorf("cannot sort by %q", column)
+	}
+	return IssueSort{Column: column, Desc: desc}, nil
+}
+
+// descFirst lists columns whose headers sort in descending order first.
+var descFirst = map[string]bool{"points": true}
+
+// Toggle returns the ?sort= value for a column header: its natural order
+// first, then the reverse if the table is already sorted by the column.
+func (s IssueSort) Toggle(column string) string {
+	desc := descFirst[column]
+	if s.Column == column && s.Desc == desc {
+		desc = !desc
+	}
+	if desc {
+		return "-" + column
+	}
+	return column
+}
+
+// Indicator returns an arrow for the header of the sorted column.
+func (s IssueSort) Indicator(column string) string {
+	switch {
+	case s.Column != column:
+		return ""
+	case s.Desc:
+		return "↓"
+	default:
+		return "↑"
+	}
+}
+
+// sortIssues sorts issues by the column, keeping the order of the report
+// among equal ones.
+func (s IssueSort) sortIssues(issues []*IssueData) {
+	compare := issueColumns[s.Column]
+	if compare == nil {
+		return
+	}
+	slices.SortStableFunc(issues, func(a, b *IssueData) int {
+		if s.Desc {
+			return compare(b, a)
+		}
+		return compare(a, b)
+	})
+}
+
+// groupByProject groups issues by project, largest projects first, and
+// sorts the issues of each project.
+func groupByProject(issues []*IssueData, sort IssueSort) []*ProjectGroup {
+	var groups []*ProjectGroup
+	byName := make(map[string]*ProjectGroup)
+	for _, issue := range issues {
+		name := cmp.Or(issue.Project, "No project")
+		g := byName[name]
+		if g == nil {
+			g = &ProjectGroup{Name: name}
+			byName[name] = g
+			groups = append(groups, g)
+		}
+		g.Points += issue.Points
+		g.Issues = append(g.Issues, issue)
+	}
+	slices.SortStableFunc(groups, func(a, b *ProjectGroup) int {
+		return cmp.Compare(b.Points, a.Points)
+	})
+	for _, g := range groups {
+		sort.sortIssues(g.Issues)
+	}
+	return groups
+}
+
+// drillDown collects the issues of a month, or of an initiative if name is
+// not empty. It returns nil if the report has no such month or initiative.
+func (r *Report) drillDown(ym yearmonth.YM, name string, sort IssueSort) *DrillDownPageData {
+	i := slices.IndexFunc(r.Months, func(md *MonthData) bool {
+		return md.Key == ym
+	})
+	if i < 0 {
+		return nil
+	}
+	data := &DrillDownPageData{Report: r, Month: r.Months[i], Sort: sort}
+
+	var issues []*IssueData
+	for _, idata := range data.Month.SortedInitiatives {
+		if name != "" {
+			if !strings.EqualFold(idata.Name, name) {
+				continue
+			}
+			data.Initiative = idata
+		}
+		issues = append(issues, idata.Issues...)
+	}
+	if name != "" && data.Initiative == nil {
+		return nil
+	}
+	for _, issue := range issues {
+		data.Total += issue.Points
+	}
+	data.Projects = groupByProject(issues, sort)
+	return data
+}
+
+// FormatCycle formats the dates of an issue's cycle, e.g. "Jul 7 – Jul 20",
+// or returns an empty string if the issue is not in a cycle.
+func (issue *IssueData) FormatCycle() string {
+	if issue.CycleStart.IsZero() {
+		return ""
+	}
+	return issue.CycleStart.Format("Jan 2") + " – " + issue.CycleEnd.Format("Jan 2")
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prairiegroupinc/linearsummarybot/yearmonth"
+)
+
+func TestDrillDown(t *testing.T) {
+	report := newMockReport()
+	feb := yearmonth.Make(2025, 2)
+	ag := report.Months[0].Initiatives["AG MVP"]
+	ag.Issues = append(ag.Issues,
+		&IssueData{Identifier: "DEV-7", Title: "Checkout", Points: 8, Project: "Cart", Schedule: Planned, Assignee: "ann", State: "Todo", DueDate: time.Date(2025, 2, 20, 0, 0, 0, 0, time.UTC)},
+		&IssueData{Identifier: "DEV-8", Title: "Payment", Points: 3, Project: "Cart", Schedule: Flex, Assignee: "Bob", DueDate: time.Date(2025, 2, 10, 0, 0, 0, 0, time.UTC)},
+	)
+
+	ids := func(data *DrillDownPageData) string {
+		var groups []string
+		for _, g := range data.Projects {
+			var issues []string
+			for _, issue := range g.Issues {
+				issues = append(issues, issue.Identifier)
+			}
+			groups = append(groups, g.Name+"="+formatPoints(g.Points)+":"+strings.Join(issues, ","))
+		}
+		return strings.Join(groups, " ")
+	}
+
+	tests := []struct {
+		name string
+		sort string
+		want string
+	}{
+		{"", "", "Cart=11:DEV-7,DEV-8 No project=6:DEV-123,DEV-225"},
+		{"ag mvp", "", "Cart=11:DEV-7,DEV-8 No project=5:DEV-123"},
+		{"AG MVP", "points", "Cart=11:DEV-8,DEV-7 No project=5:DEV-123"},
+		{"AG MVP", "-schedule", "Cart=11:DEV-8,DEV-7 No project=5:DEV-123"},
+		{"AG MVP", "due", "Cart=11:DEV-8,DEV-7 No project=5:DEV-123"},
+		{"AG MVP", "assignee", "Cart=11:DEV-7,DEV-8 No project=5:DEV-123"},
+		{"AG MVP", "-assignee", "Cart=11:DEV-8,DEV-7 No project=5:DEV-123"},
+		{"AG MVP", "state", "Cart=11:DEV-7,DEV-8 No project=5:DEV-123"},
+	}
+	for _, tt := range tests {
+		sort, err := parseIssueSort(tt.sort)
+		if err != nil {
+			t.Fatal(err)
+		}
+		data := report.drillDown(feb, tt.name, sort)
+		if data == nil {
+			t.Fatalf("drillDown(%q) = nil", tt.name)
+		}
+		if got := ids(data); got != tt.want {
+			t.Errorf("drillDown(%q, sort=%s) = %s, want %s", tt.name, tt.sort, got, tt.want)
+		}
+	}
+
+	if report.drillDown(yearmonth.Make(2025, 3), "", IssueSort{}) != nil {
+		t.Error("drillDown of a month without issues should be nil")
+	}
+	if report.drillDown(feb, "Nope", IssueSort{}) != nil {
+		t.Error("drillDown of an unknown initiative should be nil")
+	}
+	if _, err := parseIssueSort("title"); err == nil {
+		t.Error("parseIssueSort(title) should fail")
+	}
+
+	data := report.drillDown(feb, "AG MVP", IssueSort{Column: "points", Desc: true})
+	w := httptest.NewRecorder()
+	if err := renderPage(w, httptest.NewRequest("GET", "/month/2025-02/initiative/AG%20MVP", nil), "AG MVP", monthTmpl, data); err != nil {
+		t.Fatal(err)
+	}
+	page := w.Body.String()
+	for _, s := range []string{
+		`href="/month/2025-02?"`, // breadcrumb
+		"Cart", "DEV-7", "Checkout", "Todo",
+		`href="?sort=points"`, // toggles to ascending
+		`href="?sort=due"`,
+		"Points↓",
+	} {
+		if !strings.Contains(page, s) {
+			t.Errorf("page missing %q", s)
+		}
+	}
+}
+
+func TestIssueSortToggle(t *testing.T) {
+	tests := []struct {
+		sort   IssueSort
+		column string
+		want   string
+	}{
+		{IssueSort{}, "points", "-points"},
+		{IssueSort{Column: "points", Desc: true}, "points", "points"},
+		{IssueSort{Column: "points"}, "points", "-points"},
+		{IssueSort{}, "due", "due"},
+		{IssueSort{Column: "due"}, "due", "-due"},
+		{IssueSort{Column: "due", Desc: true}, "due", "due"},
+		{IssueSort{Column: "points", Desc: true}, "due", "due"},
+	}
+	for _, tt := range tests {
+		if got := tt.sort.Toggle(tt.column); got != tt.want {
+			t.Errorf("%+v.Toggle(%s) = %s, want %s", tt.sort, tt.column, got, tt.want)
+		}
+	}
+}
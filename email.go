@@ -0,0 +1,248 @@
+package main
+
+import (
+	"bytes"
+	"cmp"
+	"fmt"
+	"html/template"
+	"maps"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// EmailConfig configures the email digest, sent with -once -email.
+type EmailConfig struct {
+	// Host is the SMTP server; email is off if empty.
+	Host string `json:"smtp_host"`
+
+	// Port is the SMTP port, 587 by default.
+	Port int `json:"smtp_port"`
+
+	// Username authenticates with the password from the SMTP_PASSWORD
+	// environment variable; no authentication if empty.
+	Username string `json:"username"`
+
+	// From is the sender, e.g. "Linear Report <reports@example.com>".
+	From string `json:"from"`
+
+	// Subject defaults to "Linear report"; the team and date are appended.
+	Subject string `json:"subject"`
+
+	// Recipients lists addresses by Linear team key, e.g.
+	// {"OPS": ["ann@example.com"]}. Each team gets its own message with the
+	// report filtered to the team's issues and capacity.
+	Recipients map[string][]string `json:"recipients"`
+}
+
+func (ec *EmailConfig) init() error {
+	if ec.Host == "" {
+		return nil
+	}
+	if _, err := mail.ParseAddress(ec.From); err != nil {
+		return fmt.Errorf("from: %v", err)
+	}
+	for team, addrs := range ec.Recipients {
+		if len(addrs) == 0 {
+			return fmt.Errorf("recipients: %s: no addresses", team)
+		}
+		for _, addr := range addrs {
+			if _, err := mail.ParseAddress(addr); err != nil {
+				return fmt.Errorf("recipients: %s: %v", team, err)
+			}
+		}
+	}
+	return nil
+}
+
+func (ec *EmailConfig) addr() string {
+	return net.JoinHostPort(ec.Host, strconv.Itoa(cmp.Or(ec.Port, 587)))
+}
+
+func (ec *EmailConfig) auth() smtp.Auth {
+	if ec.Username == "" {
+		return nil
+	}
+	return smtp.PlainAuth("", ec.Username, os.Getenv("SMTP_PASSWORD"), ec.Host)
+}
+
+// resolveTeams picks the recipient lists to send to: "all" or a
+// comma-separated list of teams.
+func (ec *EmailConfig) resolveTeams(spec string) ([]string, error) {
+	if ec.Host == "" {
+		return nil, fmt.Errorf("email.smtp_host is not configured")
+	}
+	if spec == "all" {
+		return slices.Sorted(maps.Keys(ec.Recipients)), nil
+	}
+	var teams []string
+	for _, team := range strings.Split(spec, ",") {
+		team = strings.TrimSpace(team)
+		if _, ok := ec.Recipients[team]; !ok {
+			return nil, fmt.Errorf("no email recipients for team %q", team)
+		}
+		teams = append(teams, team)
+	}
+	return teams, nil
+}
+
+// sendDigests emails the recipients of each team the report built for that team.
+func sendDigests(teamReport func(team string) (*Report, error), ec *EmailConfig, teams []string) error {
+	from, err := mail.ParseAddress(ec.From)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	for _, team := range teams {
+		if len(ec.Recipients[team]) == 0 {
+			return fmt.Errorf("no email recipients for team %q", team)
+		}
+		to, err := mail.ParseAddressList(strings.Join(ec.Recipients[team], ", "))
+		if err != nil {
+			return fmt.Errorf("recipients: %s: %v", team, err)
+		}
+		report, err := teamReport(team)
+		if err != nil {
+			return err
+		}
+		subject := fmt.Sprintf("%s for %s, %s", cmp.Or(ec.Subject, "Linear report"), team, now.Format("Jan 2, 2006"))
+		msg, err := formatDigest(report, from, to, subject, now)
+		if err != nil {
+			return err
+		}
+		var rcpt []string
+		for _, a := range to {
+			rcpt = append(rcpt, a.Address)
+		}
+		if err := smtp.SendMail(ec.addr(), ec.auth(), from.Address, rcpt, msg); err != nil {
+			return fmt.Errorf("emailing %s: %w", team, err)
+		}
+	}
+	return nil
+}
+
+// DigestPageData is rendered by digest.html, a self-contained page without
+// navigation, forms, charts or links back to the server, which do not work in
+// mail clients. Links go to issues in Linear.
+type DigestPageData struct {
+	Title  string
+	Styles template.CSS
+	Report *Report
+}
+
+// formatDigest builds a multipart/alternative message with the text and HTML
+// digests of a team's report.
+func formatDigest(report *Report, from *mail.Address, to []*mail.Address, subject string, now time.Time) ([]byte, error) {
+	var html bytes.Buffer
+	err := digestTmpl.Execute(&html, DigestPageData{
+		Title:  subject,
+		Styles: inlineStyles(),
+		Report: report,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+	var toHeader []string
+	for _, a := range to {
+		toHeader = append(toHeader, a.String())
+	}
+	fmt.Fprintf(&buf, "From: %s\r\n", from)
+	fmt.Fprintf(&buf, "To: %s\r\n", strings.Join(toHeader, ", "))
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", now.Format(time.RFC1123Z))
+	fmt.Fprintf(&buf, "MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&buf, "Content-Type: multipart/alternative; boundary=%s\r\n\r\n", mw.Boundary())
+
+	parts := []struct {
+		contentType string
+		body        string
+	}{
+		{"text/plain; charset=utf-8", formatDigestText(report)},
+		{"text/html; charset=utf-8", html.String()},
+	}
+	for _, p := range parts {
+		pw, err := mw.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {p.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		qw := quotedprintable.NewWriter(pw)
+		if _, err := qw.Write([]byte(p.body)); err != nil {
+			return nil, err
+		}
+		if err := qw.Close(); err != nil {
+			return nil, err
+		}
+	}
+	if err := mw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// formatDigestText is the plain-text digest. Like the HTML digest, it leaves
+// out capacity and budgets, which are shared by all teams.
+func formatDigestText(report *Report) string {
+	var sb strings.Builder
+	const rule = "---------------------------------------------------------------------\n"
+
+	if note := report.Unit.Note(); note != "" {
+		fmt.Fprintf(&sb, "%s\n\n", note)
+	}
+
+	if atRisk := report.AtRisk(); len(atRisk) > 0 {
+		sb.WriteString("Commitments at risk:\n")
+		for _, ra := range atRisk {
+			fmt.Fprintf(&sb, "  %4s [%2s] %s: %s (due %s, %d working days left)\n", formatPercent(ra.Score), formatPoints(ra.Issue.Size), ra.Issue.Identifier, ra.Issue.Title, ra.Issue.DueDate.Format("2006-01-02"), ra.DaysLeft)
+		}
+		sb.WriteString(rule)
+	}
+
+	if len(report.Conflicts) > 0 {
+		sb.WriteString("Dependency conflicts:\n")
+		for _, c := range report.Conflicts {
+			fmt.Fprintf(&sb, "  %s\n", c.Description)
+		}
+		sb.WriteString(rule)
+	}
+
+	fmt.Fprintf(&sb, "%-45s %5s %5s %5s %5s\n", strings.ToUpper(report.Pivot), "Used", "Fixed", "Sched", "Flex")
+	sb.WriteString(rule)
+	for _, md := range report.Months {
+		fmt.Fprintf(&sb, "%-45s %5s %5s %5s %5s\n", strings.ToUpper(md.Name), formatPoints(md.Used), formatPoints(md.Fixed), formatPoints(md.Planned), formatPoints(md.Flex))
+		for _, idata := range md.SortedInitiatives {
+			fmt.Fprintf(&sb, "%-45s %5s %5s %5s %5s\n", idata.Name, formatPoints(idata.Used), formatPoints(idata.Fixed), formatPoints(idata.Planned), formatPoints(idata.Flex))
+			for _, issue := range idata.Issues {
+				fmt.Fprintf(&sb, "  [%2s] %s: %s\n", formatPoints(issue.Points), issue.Identifier, issue.Title)
+			}
+		}
+		sb.WriteString(rule)
+	}
+
+	if len(report.Milestones) > 0 {
+		sb.WriteString("Milestones:\n")
+		for _, ms := range report.Milestones {
+			status := ""
+			if ms.IsOverdue() {
+				status = "  OVERDUE"
+			}
+			fmt.Fprintf(&sb, "  %-43s %10s %5s%s\n", ms.Project+" / "+ms.Name, ms.TargetDate.Format("2006-01-02"), formatPoints(ms.Points), status)
+		}
+		sb.WriteString(rule)
+	}
+	return sb.String()
+}
@@ -0,0 +1,201 @@
+package main
+
+import (
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"net/textproto"
+	"strings"
+	"testing"
+)
+
+// smtpMessage is a message received by fakeSMTPServer.
+type smtpMessage struct {
+	From string
+	To   []string
+	Data string
+}
+
+// fakeSMTPServer accepts messages without authentication, like a local SMTP
+// stand-in, and sends them to the returned channel.
+func fakeSMTPServer(t *testing.T) (host string, port int, messages <-chan *smtpMessage) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { l.Close() })
+
+	ch := make(chan *smtpMessage, 10)
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			serveFakeSMTP(conn, ch)
+		}
+	}()
+	addr := l.Addr().(*net.TCPAddr)
+	return addr.IP.String(), addr.Port, ch
+}
+
+func serveFakeSMTP(conn net.Conn, ch chan<- *smtpMessage) {
+	defer conn.Close()
+	tc := textproto.NewConn(conn)
+	tc.PrintfLine("220 localhost ready")
+	msg := new(smtpMessage)
+	for {
+		line, err := tc.ReadLine()
+		if err != nil {
+			return
+		}
+		cmd, arg, _ := strings.Cut(line, " ")
+		switch strings.ToUpper(cmd) {
+		case "EHLO", "HELO":
+			tc.PrintfLine("250 localhost")
+		case "MAIL":
+			msg.From = strings.Trim(strings.TrimPrefix(arg, "FROM:"), "<>")
+			tc.PrintfLine("250 OK")
+		case "RCPT":
+			msg.To = append(msg.To, strings.Trim(strings.TrimPrefix(arg, "TO:"), "<>"))
+			tc.PrintfLine("250 OK")
+		case "DATA":
+			tc.PrintfLine("354 go ahead")
+			data, err := io.ReadAll(tc.DotReader())
+			if err != nil {
+				return
+			}
+			msg.Data = string(data)
+			ch <- msg
+			msg = new(smtpMessage)
+			tc.PrintfLine("250 OK")
+		case "QUIT":
+			tc.PrintfLine("221 bye")
+			return
+		default:
+			tc.PrintfLine("502 not implemented")
+		}
+	}
+}
+
+func TestSendDigests(t *testing.T) {
+	host, port, messages := fakeSMTPServer(t)
+	ec := &EmailConfig{
+		Host: host,
+		Port: port,
+		From: "Linear Report <reports@example.com>",
+		Recipients: map[string][]string{
+			"OPS": {"Ann <ann@example.com>", "bob@example.com"},
+			"DEV": {"Zoë <zoe@example.com>"},
+		},
+	}
+	if err := ec.init(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ec.resolveTeams("OPS,QA"); err == nil {
+		t.Error("resolveTeams accepted a team without recipients")
+	}
+	teams, err := ec.resolveTeams("all")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var built []string
+	teamReport := func(team string) (*Report, error) {
+		built = append(built, team)
+		report := newMockReport()
+		report.Filter.Team = team
+		return report, nil
+	}
+	if err := sendDigests(teamReport, ec, teams); err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(built, ","); got != "DEV,OPS" {
+		t.Errorf("built reports for %s, want DEV,OPS", got)
+	}
+
+	for _, want := range []struct {
+		to      string
+		subject string
+	}{
+		{"zoe@example.com", "Linear report for DEV"},
+		{"ann@example.com,bob@example.com", "Linear report for OPS"},
+	} {
+		msg := <-messages
+		if msg.From != "reports@example.com" || strings.Join(msg.To, ",") != want.to {
+			t.Errorf("envelope = %s -> %v, want reports@example.com -> %s", msg.From, msg.To, want.to)
+		}
+
+		m, err := mail.ReadMessage(strings.NewReader(msg.Data))
+		if err != nil {
+			t.Fatal(err)
+		}
+		subject, err := new(mime.WordDecoder).DecodeHeader(m.Header.Get("Subject"))
+		if err != nil || !strings.HasPrefix(subject, want.subject+", ") {
+			t.Errorf("subject = %q, %v, want %q", subject, err, want.subject)
+		}
+		if to, err := m.Header.AddressList("To"); err != nil || len(to) != len(msg.To) {
+			t.Errorf("To = %q, %v", m.Header.Get("To"), err)
+		}
+
+		mediaType, params, err := mime.ParseMediaType(m.Header.Get("Content-Type"))
+		if err != nil || mediaType != "multipart/alternative" {
+			t.Fatalf("Content-Type = %q", m.Header.Get("Content-Type"))
+		}
+		bodies := make(map[string]string)
+		mr := multipart.NewReader(m.Body, params["boundary"])
+		for {
+			p, err := mr.NextPart()
+			if err == io.EOF {
+				break
+			} else if err != nil {
+				t.Fatal(err)
+			}
+			typ, _, _ := mime.ParseMediaType(p.Header.Get("Content-Type"))
+			body, err := io.ReadAll(p) // decodes quoted-printable
+			if err != nil {
+				t.Fatal(err)
+			}
+			bodies[typ] = string(body)
+		}
+		if text := bodies["text/plain"]; !strings.Contains(text, "FEBRUARY 2025") {
+			t.Errorf("text part missing the report:\n%s", text)
+		}
+		if html := bodies["text/html"]; !strings.Contains(html, "<style>") || !strings.Contains(html, "DEV-123") || strings.Contains(html, "/static/") {
+			t.Errorf("html part should be the standalone report:\n%s", html)
+		}
+		for _, s := range []string{`href="/`, `href="?`, "<nav", "<form", "<svg", "Remaining budget"} {
+			if strings.Contains(bodies["text/html"], s) {
+				t.Errorf("html part should not contain %q", s)
+			}
+		}
+	}
+
+	ec.Recipients["QA"] = nil
+	if err := sendDigests(teamReport, ec, []string{"QA"}); err == nil {
+		t.Error("sendDigests skipped a team without recipients")
+	}
+}
+
+func TestEmailConfigInit(t *testing.T) {
+	tests := []struct {
+		ec    EmailConfig
+		valid bool
+	}{
+		{EmailConfig{}, true},
+		{EmailConfig{From: "not an address"}, true}, // off without a host
+		{EmailConfig{Host: "localhost", From: "a@example.com"}, true},
+		{EmailConfig{Host: "localhost"}, false},
+		{EmailConfig{Host: "localhost", From: "a@example.com", Recipients: map[string][]string{"OPS": {"nope"}}}, false},
+		{EmailConfig{Host: "localhost", From: "a@example.com", Recipients: map[string][]string{"OPS": {}}}, false},
+	}
+	for i, tt := range tests {
+		if err := tt.ec.init(); (err == nil) != tt.valid {
+			t.Errorf("%d: init() = %v, want valid %v", i, err, tt.valid)
+		}
+	}
+	if got := (&EmailConfig{Host: "smtp.example.com"}).addr(); got != "smtp.example.com:587" {
+		t.Errorf("addr() = %s", got)
+	}
+}
@@ -0,0 +1,210 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Explanation traces how an issue ended up where it is in the report.
+type Explanation struct {
+	Identifier string
+	Title      string
+	URL        string
+	Issue      *IssueData   // first part of the issue, nil if not scheduled
+	Parts      []*IssueData // where the issue's points went, by month and initiative
+	Steps      []string     // reasons for the schedule, month and grouping
+	Risk       *RiskAssessment
+	BlockedBy  []*DependencyNode
+	Blocks     []*DependencyNode
+	Conflicts  []*DependencyConflict
+}
+
+// IsScheduled returns whether the issue is part of the report.
+func (e *Explanation) IsScheduled() bool {
+	return e.Issue != nil
+}
+
+// Explain returns the explanation of an issue, or nil if the report knows
+// nothing about it.
+func (r *Report) Explain(identifier string) *Explanation {
+	e := &Explanation{Identifier: identifier}
+	for _, md := range r.Months {
+		for _, idata := range md.SortedInitiatives {
+			for _, issue := range idata.Issues {
+				if issue.Identifier == identifier {
+					e.Parts = append(e.Parts, issue)
+				}
+			}
+		}
+	}
+	if len(e.Parts) > 0 {
+		e.Issue = e.Parts[0]
+		e.Title, e.URL = e.Issue.Title, e.Issue.URL
+		e.Steps = r.explainSteps(e.Parts)
+	}
+
+	if n := r.Dependencies.Node(identifier); n != nil {
+		e.Title, e.URL = n.Title, n.URL
+		e.BlockedBy = r.Dependencies.lookupAll(n.BlockedBy)
+		e.Blocks = r.Dependencies.lookupAll(n.Blocks)
+	} else if e.Issue == nil {
+		return nil
+	}
+	if e.Issue == nil {
+		e.Steps = []string{"Not in the report: the issue has no estimate, has neither a cycle nor a due date, or is in a skipped state."}
+	}
+
+	for _, ra := range r.Risks {
+		if ra.Issue.Identifier == identifier {
+			e.Risk = ra
+		}
+	}
+	e.Conflicts = r.ConflictsOf(identifier)
+	return e
+}
+
+func (r *Report) explainSteps(parts []*IssueData) []string {
+	issue := parts[0]
+	var steps []string
+
+	// Schedule
+	const day = "Jan 2, 2006"
+	switch issue.Schedule {
+	case Fixed:
+		steps = append(steps, fmt.Sprintf("Fixed: due %s, within 14 days of the end of its cycle on %s.", issue.DueDate.Format(day), issue.CycleEnd.Format(day)))
+	case Planned:
+		if issue.DueDate.IsZero() {
+			steps = append(steps, fmt.Sprintf("Planned: in a cycle ending %s, without a due date.", issue.CycleEnd.Format(day)))
+		} else {
+			steps = append(steps, fmt.Sprintf("Planned: in a cycle ending %s, due %s, more than 14 days later.", issue.CycleEnd.Format(day), issue.DueDate.Format(day)))
+		}
+	case Flex:
+		steps = append(steps, fmt.Sprintf("Flex: due %s, not in a cycle.", issue.DueDate.Format(day)))
+	}
+	normalized := config.normalizeEstimate(issue.TeamKey, issue.Estimate)
+	if normalized != float64(issue.Estimate) {
+		steps = append(steps, fmt.Sprintf("Estimate %d on the %s scale is %s normalized points.", issue.Estimate, issue.TeamKey, formatPoints(normalized)))
+	}
+	if r.IsHours() && normalized > 0 {
+		steps = append(steps, fmt.Sprintf("Converted at %s hours per point, %s hours in total.", formatPoints(issue.Size/normalized), formatPoints(issue.Size)))
+	}
+
+	// Target month
+	if issue.CycleStart.IsZero() {
+		steps = append(steps, "Targets the month of its due date.")
+	} else {
+		mid := issue.CycleStart.Add(issue.CycleEnd.Sub(issue.CycleStart) / 2)
+		if !issue.DueDate.IsZero() && !issue.DueDate.Before(issue.CycleStart) && issue.DueDate.Before(mid) {
+			steps = append(steps, "Targets the month of its due date, which falls in the first half of its cycle.")
+		} else {
+			steps = append(steps, fmt.Sprintf("Targets the middle of its cycle (%s to %s), %s.", issue.CycleStart.Format(day), issue.CycleEnd.Format(day), mid.Format(day)))
+		}
+	}
+	var months, groups []string
+	for _, p := range parts {
+		if !slices.Contains(months, p.MonthName) {
+			months = append(months, p.MonthName)
+		}
+		if !slices.Contains(groups, p.InitName) {
+			groups = append(groups, p.InitName)
+		}
+	}
+	if len(months) > 1 {
+		steps = append(steps, fmt.Sprintf("Spread by working days across %s.", strings.Join(months, ", ")))
+	} else {
+		steps = append(steps, fmt.Sprintf("Counts towards %s.", months[0]))
+	}
+
+	// Grouping
+	switch {
+	case r.Pivot != "":
+		if issue.Dimensions[r.Pivot] == nil {
+			steps = append(steps, fmt.Sprintf("Has no %s label, so it is listed under Other.", r.Pivot))
+		} else {
+			steps = append(steps, fmt.Sprintf("Broken down by %s: %s.", r.Pivot, strings.Join(groups, ", ")))
+		}
+	case issue.Bucket != "":
+		steps = append(steps, fmt.Sprintf("Counts towards the %s bucket because of its label.", issue.Bucket))
+	case len(issue.Initiatives) > 1 && len(groups) > 1:
+		steps = append(steps, fmt.Sprintf("Split among the initiatives of project %s (%s attribution): %s.", issue.Project, config.InitiativeAttribution.Mode, strings.Join(groups, ", ")))
+	case len(issue.Initiatives) > 1:
+		steps = append(steps, fmt.Sprintf("Attributed to %s, the primary of the initiatives of project %s: %s.", groups[0], issue.Project, strings.Join(issue.Initiatives, ", ")))
+	case len(issue.Initiatives) == 1:
+		steps = append(steps, fmt.Sprintf("Belongs to initiative %s through project %s.", groups[0], issue.Project))
+	case issue.Project != "":
+		steps = append(steps, fmt.Sprintf("Project %s has no initiative, so the issue is listed under the project.", issue.Project))
+	default:
+		steps = append(steps, "Has no project, so it is listed under Other.")
+	}
+
+	// Capacity line
+	for _, md := range r.Months {
+		if md.IsPast {
+			continue
+		}
+		for i, ranked := range md.Ranked {
+			if ranked.Issue.Identifier != issue.Identifier {
+				continue
+			}
+			where := "above the capacity line"
+			if !ranked.Fits {
+				where = "below the capacity line, at risk"
+			}
+			steps = append(steps, fmt.Sprintf("Ranked #%d of %d in %s with %s cumulative points of %s, %s.", i+1, len(md.Ranked), md.Name, formatPoints(ranked.Cumulative), formatPoints(md.IssueCapacity()), where))
+		}
+	}
+	return steps
+}
+
+func formatExplanationText(e *Explanation) string {
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "%s: %s\n", e.Identifier, e.Title)
+	if e.URL != "" {
+		fmt.Fprintf(&sb, "%s\n", e.URL)
+	}
+	sb.WriteString("---------------------------------------------------------------------\n")
+	for _, step := range e.Steps {
+		fmt.Fprintf(&sb, "* %s\n", step)
+	}
+
+	if len(e.Parts) > 0 {
+		sb.WriteString("\nPoints:\n")
+		for _, p := range e.Parts {
+			fmt.Fprintf(&sb, "  %-20s %-40s %5s\n", p.MonthName, p.InitName, formatPoints(p.Points))
+		}
+	}
+
+	if e.Risk != nil {
+		fmt.Fprintf(&sb, "\nRisk: %s", formatPercent(e.Risk.Score))
+		if e.Risk.AtRisk {
+			sb.WriteString(" (at risk)")
+		}
+		sb.WriteString("\n")
+		for _, f := range e.Risk.Factors {
+			fmt.Fprintf(&sb, "  %-20s %5s x%s\n", f.Name, formatPercent(f.Value), formatPoints(f.Weight))
+		}
+	}
+
+	printNodes := func(title string, nodes []*DependencyNode) {
+		if len(nodes) == 0 {
+			return
+		}
+		fmt.Fprintf(&sb, "\n%s:\n", title)
+		for _, n := range nodes {
+			fmt.Fprintf(&sb, "  %s: %s (%s)\n", n.Identifier, n.Title, n.Status())
+		}
+	}
+	printNodes("Blocked by", e.BlockedBy)
+	printNodes("Blocks", e.Blocks)
+
+	if len(e.Conflicts) > 0 {
+		sb.WriteString("\nConflicts:\n")
+		for _, c := range e.Conflicts {
+			fmt.Fprintf(&sb, "  %s\n", c.Description)
+		}
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"cmp"
+	"encoding/csv"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+func init() {
+	registerFormat(&Format{Name: "csv", Extension: "csv", ContentType: "text/csv; charset=utf-8", Attachment: true, Renderer: RenderFunc(renderCSVReport)})
+}
+
+// renderCSVReport writes the issue table, or the summary if opts.Sheet is "summary".
+func renderCSVReport(w io.Writer, report *Report, opts RenderOptions) error {
+	if opts.Sheet == "summary" {
+		return writeCSV(w, summarySheet(report))
+	}
+	return writeCSV(w, issueSheet("Issues", report, report.Months))
+}
+
+// Sheet is a table exported to CSV or a spreadsheet. Cells are strings or
+// float64 numbers; the first row is the header.
+type Sheet struct {
+	Name string
+	Rows [][]any
+}
+
+// issueSheet lists the issues of the given months, one row per part of a
+// split issue.
+func issueSheet(name string, report *Report, months []*MonthData) *Sheet {
+	s := &Sheet{Name: name}
+	s.Rows = append(s.Rows, []any{"Identifier", "Title", "Points", "Share", "Schedule", "Month", cmp.Or(report.Pivot, "Initiative"), "Bucket", "Clients", "Labels", "URL"})
+	for _, md := range months {
+		for _, idata := range md.SortedInitiatives {
+			for _, issue := range idata.Issues {
+				share := issue.Share
+				if !issue.IsSplit() {
+					share = 1
+				}
+				s.Rows = append(s.Rows, []any{
+					issue.Identifier,
+					issue.Title,
+					issue.Points,
+					share,
+					issue.Schedule.String(),
+					md.Name,
+					idata.Name,
+					issue.Bucket,
+					strings.Join(issue.Clients, ", "),
+					strings.Join(issue.Labels, ", "),
+					issue.URL,
+				})
+			}
+		}
+	}
+	return s
+}
+
+// summarySheet lists a row per month, followed by a row per initiative of the month.
+func summarySheet(report *Report) *Sheet {
+	s := &Sheet{Name: "Summary"}
+	s.Rows = append(s.Rows, []any{"Month", cmp.Or(report.Pivot, "Initiative"), "Capacity", "Budget", "Total", "Used", "Fixed", "Planned", "Flex"})
+	for _, md := range report.Months {
+		var budget float64
+		for _, idata := range md.SortedInitiatives {
+			budget += idata.EffectiveBudget()
+		}
+		s.Rows = append(s.Rows, []any{md.Name, "", md.Capacity, budget, md.Total, md.Used, md.Fixed, md.Planned, md.Flex})
+		for _, idata := range md.SortedInitiatives {
+			s.Rows = append(s.Rows, []any{md.Name, idata.Name, "", idata.EffectiveBudget(), idata.Total, idata.Used, idata.Fixed, idata.Planned, idata.Flex})
+		}
+	}
+	return s
+}
+
+// workbookSheets returns the summary and a sheet of issues per month.
+func workbookSheets(report *Report) []*Sheet {
+	sheets := []*Sheet{summarySheet(report)}
+	for _, md := range report.Months {
+		sheets = append(sheets, issueSheet(md.Name, report, []*MonthData{md}))
+	}
+	return sheets
+}
+
+func writeCSV(w io.Writer, s *Sheet) error {
+	cw := csv.NewWriter(w)
+	for _, row := range s.Rows {
+		record := make([]string, len(row))
+		for i, cell := range row {
+			record[i] = formatCell(cell)
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// formatCell formats numbers with up to two decimals, so that splits stay
+// readable without losing much precision. Strings that spreadsheets would
+// run as formulas, like a title starting with "=", are prefixed with a quote.
+func formatCell(cell any) string {
+	switch v := cell.(type) {
+	case float64:
+		return strconv.FormatFloat(math.Round(v*100)/100, 'f', -1, 64)
+	case string:
+		if v != "" && strings.ContainsRune("=+-@\t\r", rune(v[0])) {
+			return "'" + v
+		}
+		return v
+	default:
+		return ""
+	}
+}
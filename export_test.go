@@ -0,0 +1,92 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestWriteCSV(t *testing.T) {
+	report := newMockReport()
+	report.Months[0].SortedInitiatives[0].Issues[0].Share = 1.0 / 3
+	report.Months[0].SortedInitiatives[0].Issues[0].Labels = []string{"Client-Acme", "Bug"}
+	report.Months[0].SortedInitiatives[1].Issues[0].Title = "=HYPERLINK(\"http://example.com\")"
+
+	var buf bytes.Buffer
+	if err := writeCSV(&buf, issueSheet("Issues", report, report.Months)); err != nil {
+		t.Fatal(err)
+	}
+	want := `Identifier,Title,Points,Share,Schedule,Month,Initiative,Bucket,Clients,Labels,URL
+DEV-123,Implement feature X,5,0.33,Fixed,February 2025,AG MVP,,,"Client-Acme, Bug",
+DEV-225,"'=HYPERLINK(""http://example.com"")",1,1,Fixed,February 2025,Other,,,,
+`
+	if got := buf.String(); got != want {
+		t.Errorf("writeCSV() =\n%s\nwant\n%s", got, want)
+	}
+
+	buf.Reset()
+	if err := writeCSV(&buf, summarySheet(report)); err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Split(strings.TrimSpace(buf.String()), "\n"); len(lines) != 4 || lines[1] != "February 2025,,0,0,82,0,81,1,0" {
+		t.Errorf("summary =\n%s", buf.String())
+	}
+}
+
+func TestWriteXLSX(t *testing.T) {
+	report := newMockReport()
+	report.Months[0].Name = "February 2025 [draft]"
+
+	var buf bytes.Buffer
+	if err := writeXLSX(&buf, workbookSheets(report)); err != nil {
+		t.Fatal(err)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := make(map[string]string)
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, _ := io.ReadAll(rc)
+		rc.Close()
+		files[f.Name] = string(data)
+
+		// every part must be well-formed XML
+		dec := xml.NewDecoder(bytes.NewReader(data))
+		for {
+			if _, err := dec.Token(); err == io.EOF {
+				break
+			} else if err != nil {
+				t.Fatalf("%s: %v", f.Name, err)
+			}
+		}
+	}
+
+	for _, name := range []string{"[Content_Types].xml", "_rels/.rels", "xl/workbook.xml", "xl/_rels/workbook.xml.rels", "xl/worksheets/sheet1.xml", "xl/worksheets/sheet2.xml"} {
+		if _, ok := files[name]; !ok {
+			t.Errorf("missing %s", name)
+		}
+	}
+	if wb := files["xl/workbook.xml"]; !strings.Contains(wb, `name="Summary"`) || !strings.Contains(wb, `name="February 2025 -draft-"`) {
+		t.Errorf("unexpected sheet names in %s", wb)
+	}
+	if sheet := files["xl/worksheets/sheet2.xml"]; !strings.Contains(sheet, `<c r="C2"><v>5</v></c>`) || !strings.Contains(sheet, `<t xml:space="preserve">Implement feature X</t>`) {
+		t.Errorf("unexpected issue sheet %s", sheet)
+	}
+}
+
+func TestXLSXColumn(t *testing.T) {
+	for i, want := range map[int]string{0: "A", 25: "Z", 26: "AA", 27: "AB", 701: "ZZ", 702: "AAA"} {
+		if got := xlsxColumn(i); got != want {
+			t.Errorf("xlsxColumn(%d) = %s, want %s", i, got, want)
+		}
+	}
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/prairiegroupinc/linearsummarybot/yearmonth"
+)
+
+// ReportFilter narrows a report down to some of its issues. Zero fields
+// match everything.
+type ReportFilter struct {
+	From yearmonth.YM // first month, inclusive
+	To   yearmonth.YM // last month, inclusive
+
+	Initiative string // initiative or bucket an issue is attributed to
+	Client     string
+	Label      string
+	Team       string // Linear team key
+	Schedule   Schedule
+}
+
+func (f ReportFilter) IsEmpty() bool {
+	return f == ReportFilter{}
+}
+
+// Match reports whether a part of an issue passes the filter. It is applied
+// after spreading issues across months and initiatives, so that a part is
+// kept or dropped on its own.
+func (f ReportFilter) Match(issue *IssueData) bool {
+	if f.From != 0 && issue.YearMonth < f.From {
+		return false
+	}
+	if f.To != 0 && issue.YearMonth > f.To {
+		return false
+	}
+	if f.Initiative != "" && !strings.EqualFold(issue.InitName, f.Initiative) {
+		return false
+	}
+	if f.Client != "" && !containsFold(issue.Clients, f.Client) {
+		return false
+	}
+	if f.Label != "" && !containsFold(issue.Labels, f.Label) {
+		return false
+	}
+	if f.Team != "" && !strings.EqualFold(issue.TeamKey, f.Team) {
+		return false
+	}
+	if f.Schedule != Unscheduled && issue.Schedule != f.Schedule {
+		return false
+	}
+	return true
+}
+
+// Values returns the query parameters that select this filter.
+func (f ReportFilter) Values() url.Values {
+	v := url.Values{}
+	if f.From != 0 {
+		v.Set("from", f.From.String())
+	}
+	if f.To != 0 {
+		v.Set("to", f.To.String())
+	}
+	if f.Initiative != "" {
+		v.Set("initiative", f.Initiative)
+	}
+	if f.Client != "" {
+		v.Set("client", f.Client)
+	}
+	if f.Schedule != Unscheduled {
+		v.Set("schedule", strings.ToLower(f.Schedule.String()))
+	}
+	if f.Label != "" {
+		v.Set("label", f.Label)
+	}
+	if f.Team != "" {
+		v.Set("team", f.Team)
+	}
+	return v
+}
+
+// parseFilter reads a filter from query parameters like ?from=2025-05&schedule=fixed.
+func parseFilter(q url.Values) (ReportFilter, error) {
+	var f ReportFilter
+	var err error
+	if s := q.Get("from"); s != "" {
+		if f.From, err = yearmonth.Parse(s); err != nil {
+			return f, fmt.Errorf("from: %q: %v", s, err)
+		}
+	}
+	if s := q.Get("to"); s != "" {
+		if f.To, err = yearmonth.Parse(s); err != nil {
+			return f, fmt.Errorf("to: %q: %v", s, err)
+		}
+	}
+	if f.From != 0 && f.To != 0 && f.From > f.To {
+		return f, fmt.Errorf("from %v is after to %v", f.From, f.To)
+	}
+	if s := q.Get("schedule"); s != "" {
+		if f.Schedule, err = parseSchedule(s); err != nil {
+			return f, err
+		}
+	}
+	f.Initiative = strings.TrimSpace(q.Get("initiative"))
+	f.Client = strings.TrimSpace(q.Get("client"))
+	f.Label = strings.TrimSpace(q.Get("label"))
+	f.Team = strings.TrimSpace(q.Get("team"))
+	return f, nil
+}
+
+func parseSchedule(s string) (Schedule, error) {
+	for _, sch := range []Schedule{Fixed, Planned, Flex} {
+		if strings.EqualFold(s, sch.String()) {
+			return sch, nil
+		}
+	}
+	return Unscheduled, fmt.Errorf("unknown schedule %q, expected fixed, planned or flex", s)
+}
+
+func containsFold(values []string, s string) bool {
+	return slices.ContainsFunc(values, func(v string) bool {
+		return strings.EqualFold(v, s)
+	})
+}
+
+// Query returns the query parameters that reproduce the report: its pivot,
+// unit and filter.
+func (r *Report) Query() url.Values {
+	v := r.Filter.Values()
+	if r.Pivot != "" {
+		v.Set("pivot", r.Pivot)
+	}
+	if r.IsHours() {
+		v.Set("unit", string(r.Unit))
+	}
+	return v
+}
@@ -0,0 +1,191 @@
+package main
+
+import (
+	"encoding/json"
+	"net/url"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/prairiegroupinc/linearsummarybot/yearmonth"
+)
+
+func TestParseFilter(t *testing.T) {
+	tests := []struct {
+		query string
+		want  ReportFilter
+		err   bool
+	}{
+		{"", ReportFilter{}, false},
+		{"from=2025-05&to=2025-09", ReportFilter{From: yearmonth.Make(2025, 5), To: yearmonth.Make(2025, 9)}, false},
+		{"from=2025-09&to=2025-05", ReportFilter{}, true},
+		{"from=May", ReportFilter{}, true},
+		{"schedule=Fixed&initiative=+AG+MVP+&client=Acme&label=Bug", ReportFilter{Schedule: Fixed, Initiative: "AG MVP", Client: "Acme", Label: "Bug"}, false},
+		{"schedule=soon", ReportFilter{}, true},
+		{"team=OPS", ReportFilter{Team: "OPS"}, false},
+	}
+	for _, tt := range tests {
+		q, err := url.ParseQuery(tt.query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := parseFilter(q)
+		if (err != nil) != tt.err {
+			t.Errorf("parseFilter(%s) error = %v, want error %v", tt.query, err, tt.err)
+		} else if err == nil && got != tt.want {
+			t.Errorf("parseFilter(%s) = %+v, want %+v", tt.query, got, tt.want)
+		}
+		if err == nil {
+			again, _ := parseFilter(got.Values())
+			if again != got {
+				t.Errorf("parseFilter(%s).Values() = %s does not round-trip", tt.query, got.Values().Encode())
+			}
+		}
+	}
+}
+
+func TestFilteredReport(t *testing.T) {
+	var issues []LinearIssue
+	err := json.Unmarshal([]byte(`[
+		{"identifier": "DEV-1", "estimate": 5, "dueDate": "2025-05-10", "labels": {"nodes": [{"name": "Client-Acme"}]},
+		 "project": {"name": "Checkout", "initiatives": {"nodes": [{"name": "Growth"}]}}},
+		{"identifier": "DEV-2", "estimate": 3, "dueDate": "2025-07-20", "labels": {"nodes": [{"name": "Bug"}]},
+		 "project": {"name": "Search", "initiatives": {"nodes": [{"name": "Growth"}]}}},
+		{"identifier": "DEV-3", "estimate": 2, "dueDate": "2025-07-05", "labels": {"nodes": [{"name": "Client-Globex"}, {"name": "Bug"}]}},
+		{"identifier": "DEV-4", "estimate": 1, "dueDate": "2025-09-05", "team": {"key": "OPS", "name": "Operations"}}
+	]`), &issues)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	saved := config
+	defer func() { config = saved }()
+	config.ByMonth = map[yearmonth.YM]*MonthConfig{
+		yearmonth.Make(2025, 7): {Capacity: 20, Budget: map[string]BudgetAmount{"Reliability": {Points: 4}}},
+	}
+	config.Clients = map[string]*ClientConfig{}
+	config.Dimensions = defaultDimensions
+
+	tests := []struct {
+		filter ReportFilter
+		want   map[string]float64 // month -> used
+	}{
+		{ReportFilter{}, map[string]float64{"2025-05": 5, "2025-07": 5, "2025-09": 1}},
+		{ReportFilter{From: yearmonth.Make(2025, 6), To: yearmonth.Make(2025, 8)}, map[string]float64{"2025-07": 5}},
+		{ReportFilter{Initiative: "growth"}, map[string]float64{"2025-05": 5, "2025-07": 3}},
+		{ReportFilter{Client: "Globex"}, map[string]float64{"2025-07": 2}},
+		{ReportFilter{Label: "bug", To: yearmonth.Make(2025, 7)}, map[string]float64{"2025-07": 5}},
+		{ReportFilter{Schedule: Fixed}, map[string]float64{}},
+		{ReportFilter{Team: "ops"}, map[string]float64{"2025-09": 1}},
+	}
+	for _, tt := range tests {
+		report, err := computeReport(issues, nil, ReportOptions{Filter: tt.filter})
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := make(map[string]float64)
+		for _, md := range report.Months {
+			got[md.Key.String()] = md.Used
+		}
+		if len(got) != len(tt.want) {
+			t.Errorf("%+v: months = %v, want %v", tt.filter, got, tt.want)
+			continue
+		}
+		for month, used := range tt.want {
+			if got[month] != used {
+				t.Errorf("%+v: months = %v, want %v", tt.filter, got, tt.want)
+				break
+			}
+		}
+		if report.Filter != tt.filter {
+			t.Errorf("%+v: report.Filter = %+v", tt.filter, report.Filter)
+		}
+	}
+
+	report, err := computeReport(issues, nil, ReportOptions{Filter: ReportFilter{Initiative: "Growth"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, md := range report.Months {
+		for name := range md.Initiatives {
+			if name != "Growth" {
+				t.Errorf("%s: unexpected initiative %q in a report filtered by Growth", md.Name, name)
+			}
+		}
+	}
+	if got, want := report.Query().Encode(), "initiative=Growth"; got != want {
+		t.Errorf("Query() = %s, want %s", got, want)
+	}
+}
+
+func TestFilteredBudgets(t *testing.T) {
+	this := yearmonth.FromTime(time.Now().UTC())
+	next := this.Next()
+	due := func(ym yearmonth.YM) string {
+		return ym.Time().AddDate(0, 0, 27).Format(time.DateOnly)
+	}
+	var issues []LinearIssue
+	err := json.Unmarshal([]byte(`[
+		{"identifier": "DEV-1", "estimate": 4, "dueDate": "`+due(this)+`", "labels": {"nodes": [{"name": "Reliability"}, {"name": "Client-Acme"}]}, "team": {"key": "OPS"}},
+		{"identifier": "DEV-2", "estimate": 3, "dueDate": "`+due(this)+`", "labels": {"nodes": [{"name": "Reliability"}]}},
+		{"identifier": "DEV-3", "estimate": 2, "dueDate": "`+due(next)+`", "labels": {"nodes": [{"name": "Reliability"}]}}
+	]`), &issues)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	saved := config
+	defer func() { config = saved }()
+	config.DefaultCapacity = 20
+	config.DefaultBudget = map[string]BudgetAmount{"Reliability": {Points: 10}}
+	config.ByMonth = nil
+	config.CarryOver = map[string]*CarryOverConfig{"Reliability": {Mode: CarryForward}}
+	config.Clients = map[string]*ClientConfig{}
+	config.Dimensions = append(slices.Clip(defaultDimensions), &DimensionConfig{Name: BucketDimension, Labels: map[string]string{"Reliability": "Reliability"}})
+
+	// The balance counts all issues in the bucket, whatever the filter shows
+	tests := []struct {
+		filter    ReportFilter
+		months    int
+		used      float64 // of the bucket in the first month shown
+		carriedIn float64 // into the first month shown
+		entries   int
+		charged   float64 // to the bucket in the first ledger entry
+	}{
+		{ReportFilter{}, 2, 7, 0, 2, 7},
+		{ReportFilter{Client: "Acme"}, 1, 4, 0, 2, 7},
+		{ReportFilter{From: next}, 1, 2, 3, 1, 2},
+		{ReportFilter{Initiative: "Other"}, 0, 0, 0, 0, 0},
+		{ReportFilter{Team: "OPS"}, 1, 4, 0, 0, 0}, // budgets are shared by all teams
+	}
+	for _, tt := range tests {
+		report, err := computeReport(issues, nil, ReportOptions{Filter: tt.filter})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(report.Months) != tt.months {
+			t.Errorf("%+v: got %d months, want %d", tt.filter, len(report.Months), tt.months)
+			continue
+		}
+		if tt.months > 0 {
+			idata := report.Months[0].Initiatives["Reliability"]
+			if idata.Used != tt.used || idata.CarriedIn != tt.carriedIn {
+				t.Errorf("%+v: Reliability used %v, carried in %v, want %v, %v", tt.filter, idata.Used, idata.CarriedIn, tt.used, tt.carriedIn)
+			}
+			if tt.filter.Team != "" && idata.Total != idata.Used {
+				t.Errorf("%+v: Reliability total %v, want only the team's %v", tt.filter, idata.Total, idata.Used)
+			}
+		}
+		var entries []*LedgerEntry
+		for _, bl := range report.Budgets {
+			entries = append(entries, bl.Entries...)
+		}
+		if len(entries) != tt.entries {
+			t.Errorf("%+v: got %d ledger entries, want %d", tt.filter, len(entries), tt.entries)
+			continue
+		}
+		if tt.entries > 0 && entries[0].Used != tt.charged {
+			t.Errorf("%+v: first ledger entry used %v, want %v", tt.filter, entries[0].Used, tt.charged)
+		}
+	}
+}
@@ -0,0 +1,227 @@
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Built-in grouping dimensions. Label dimensions from config.json can be used too.
+const (
+	GroupMonth      = "month"
+	GroupInitiative = "initiative"
+	GroupProject    = "project"
+	GroupTeam       = "team"
+	GroupAssignee   = "assignee"
+	GroupSchedule   = "schedule"
+)
+
+var builtinGroupings = []string{GroupMonth, GroupInitiative, GroupProject, GroupTeam, GroupAssignee, GroupSchedule}
+
+// defaultGrouping matches the layout of the main report.
+var defaultGrouping = []string{GroupMonth, GroupInitiative}
+
+// unusedBudgetKey groups the budget reserved but not used by any issue on
+// dimensions other than month and initiative.
+const unusedBudgetKey = "Unused budget"
+
+// GroupNode is a node of a report grouped by an ordered list of dimensions.
+// The root holds the grand total; each level below breaks its parent down by
+// the next dimension, and leaves hold the issues.
+type GroupNode struct {
+	Dimension string `json:"dimension,omitempty"` // empty for the root
+	Key       string `json:"key,omitempty"`
+	Name      string `json:"name,omitempty"`
+	Depth     int    `json:"-"`
+	Unit      Unit   `json:"unit,omitempty"` // root only
+
+	Capacity float64 `json:"capacity,omitempty"` // month nodes only
+	Fixed    float64 `json:"fixed"`
+	Planned  float64 `json:"planned"`
+	Flex     float64 `json:"flex"`
+	Used     float64 `json:"used"`
+	Budget   float64 `json:"budget"`
+	Total    float64 `json:"total"` // Used plus the unused part of budgets
+
+	Children []*GroupNode `json:"children,omitempty"`
+	Issues   []*IssueData `json:"issues,omitempty"` // leaves only
+
+	sortKey  string
+	children map[string]*GroupNode
+}
+
+func (n *GroupNode) HasCapacity() bool {
+	return n.Capacity > 0
+}
+
+func (n *GroupNode) RemainingBudget() float64 {
+	return n.Capacity - n.Total
+}
+
+func (n *GroupNode) IsOverCapacity() bool {
+	return n.HasCapacity() && n.RemainingBudget() < 0
+}
+
+// groupEntry is a single contribution to the grouped report: either an issue
+// or the unused part of an initiative's budget in a month.
+type groupEntry struct {
+	month    *MonthData
+	idata    *InitiativeData
+	issue    *IssueData // nil for unused budget
+	reserved float64
+	budget   float64
+}
+
+// parseGrouping parses a comma-separated list of dimensions, e.g. "month,initiative,project".
+func parseGrouping(s string) ([]string, error) {
+	var dims []string
+	for _, item := range strings.Split(s, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		dim := strings.ToLower(item)
+		if !slices.Contains(builtinGroupings, dim) {
+			dc := config.Dimension(item)
+			if dc == nil {
+				return nil, fmt.Errorf("unknown grouping dimension %q", item)
+			}
+			dim = dc.Name
+		}
+		if slices.Contains(dims, dim) {
+			return nil, fmt.Errorf("duplicate grouping dimension %q", item)
+		}
+		dims = append(dims, dim)
+	}
+	if len(dims) == 0 {
+		return nil, fmt.Errorf("no grouping dimensions")
+	}
+	return dims, nil
+}
+
+// groupReport regroups the issues and budgets of a report by the given dimensions.
+func groupReport(report *Report, dims []string) *GroupNode {
+	root := &GroupNode{Unit: report.Unit}
+	for _, md := range report.Months {
+		for _, idata := range md.SortedInitiatives {
+			for _, issue := range idata.Issues {
+				root.add(&groupEntry{month: md, idata: idata, issue: issue}, dims, 1)
+			}
+			if budget := idata.EffectiveBudget(); budget > 0 {
+				root.add(&groupEntry{month: md, idata: idata, reserved: idata.Total - idata.Used, budget: budget}, dims, 1)
+			}
+		}
+	}
+	root.finish()
+	return root
+}
+
+func (n *GroupNode) add(e *groupEntry, dims []string, weight float64) {
+	if e.issue != nil {
+		points := e.issue.Points * weight
+		switch e.issue.Schedule {
+		case Fixed:
+			n.Fixed += points
+		case Planned:
+			n.Planned += points
+		case Flex:
+			n.Flex += points
+		}
+		n.Used += points
+		n.Total += points
+	} else {
+		n.Budget += e.budget * weight
+		n.Total += e.reserved * weight
+	}
+
+	if len(dims) == 0 {
+		if e.issue != nil {
+			issue := e.issue
+			if weight < 1 {
+				part := *issue
+				part.Points *= weight
+				part.Share *= weight
+				issue = &part
+			}
+			n.Issues = append(n.Issues, issue)
+		}
+		return
+	}
+
+	keys := e.keys(dims[0])
+	for _, key := range keys {
+		n.child(dims[0], key, e).add(e, dims[1:], weight/float64(len(keys)))
+	}
+}
+
+func (n *GroupNode) child(dim, key string, e *groupEntry) *GroupNode {
+	c := n.children[key]
+	if c == nil {
+		c = &GroupNode{
+			Dimension: dim,
+			Key:       key,
+			Name:      key,
+			Depth:     n.Depth + 1,
+		}
+		if dim == GroupMonth {
+			c.Name = e.month.Name
+			c.sortKey = e.month.Key.String()
+			if n.Depth == 0 {
+				c.Capacity = e.month.Capacity // not meaningful for a part of a month
+			}
+		}
+		if n.children == nil {
+			n.children = make(map[string]*GroupNode)
+		}
+		n.children[key] = c
+		n.Children = append(n.Children, c)
+	}
+	return c
+}
+
+// keys returns the values of the given dimension for the entry; entries with
+// several values are split evenly among them.
+func (e *groupEntry) keys(dim string) []string {
+	switch dim {
+	case GroupMonth:
+		return []string{e.month.Key.String()}
+	case GroupInitiative:
+		return []string{e.idata.Name}
+	}
+	if e.issue == nil {
+		return []string{unusedBudgetKey}
+	}
+	switch dim {
+	case GroupProject:
+		return []string{cmp.Or(e.issue.Project, "No project")}
+	case GroupTeam:
+		return []string{cmp.Or(e.issue.Team, "No team")}
+	case GroupAssignee:
+		return []string{cmp.Or(e.issue.Assignee, "Unassigned")}
+	case GroupSchedule:
+		return []string{e.issue.Schedule.String()}
+	}
+	if values := e.issue.Dimensions[dim]; len(values) > 0 {
+		return values
+	}
+	return []string{"No " + dim}
+}
+
+// finish sorts months chronologically, other groups by total (descending),
+// and issues within leaves.
+func (n *GroupNode) finish() {
+	slices.SortFunc(n.Children, func(a, b *GroupNode) int {
+		if a.sortKey != "" || b.sortKey != "" {
+			return cmp.Compare(a.sortKey, b.sortKey)
+		}
+		if c := cmp.Compare(b.Total, a.Total); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.Name, b.Name)
+	})
+	for _, c := range n.Children {
+		c.finish()
+	}
+	sortIssues(n.Issues)
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/prairiegroupinc/linearsummarybot/yearmonth"
+)
+
+func TestGroupReport(t *testing.T) {
+	var issues []LinearIssue
+	err := json.Unmarshal([]byte(`[
+		{"identifier": "DEV-1", "estimate": 5, "dueDate": "2025-07-10", "team": {"name": "Web"}, "assignee": {"name": "Ann"},
+		 "project": {"name": "Checkout", "initiatives": {"nodes": [{"name": "Growth"}]}}},
+		{"identifier": "DEV-2", "estimate": 3, "dueDate": "2025-07-20", "team": {"name": "Web"},
+		 "project": {"name": "Search", "initiatives": {"nodes": [{"name": "Growth"}]}}},
+		{"identifier": "DEV-3", "estimate": 2, "dueDate": "2025-08-05", "team": {"name": "Mobile"}, "assignee": {"name": "Ann"}}
+	]`), &issues)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	saved := config
+	defer func() { config = saved }()
+	config.ByMonth = map[yearmonth.YM]*MonthConfig{
+		yearmonth.Make(2025, 7): {Capacity: 20, Budget: map[string]BudgetAmount{"Reliability": {Points: 4}}},
+	}
+
+	report, err := computeReport(issues, nil, ReportOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	root := groupReport(report, []string{GroupMonth, GroupInitiative, GroupProject})
+	if root.Used != 10 || root.Budget != 4 || root.Total != 14 {
+		t.Errorf("root = used %v, budget %v, total %v, want 10, 4, 14", root.Used, root.Budget, root.Total)
+	}
+	if len(root.Children) != 2 || root.Children[0].Name != "July 2025" || root.Children[1].Name != "August 2025" {
+		t.Fatalf("months not grouped chronologically: %+v", root.Children)
+	}
+
+	july := root.Children[0]
+	if july.Capacity != 20 || july.Total != 12 {
+		t.Errorf("July = capacity %v, total %v, want 20, 12", july.Capacity, july.Total)
+	}
+	if len(july.Children) != 2 || july.Children[0].Name != "Growth" || july.Children[1].Name != "Reliability" {
+		t.Fatalf("July initiatives = %+v", july.Children)
+	}
+	growth := july.Children[0]
+	if len(growth.Children) != 2 || growth.Children[0].Name != "Checkout" || growth.Children[0].Flex != 5 || growth.Children[1].Name != "Search" {
+		t.Errorf("Growth projects = %+v", growth.Children)
+	}
+	if reliability := july.Children[1]; len(reliability.Children) != 1 || reliability.Children[0].Name != unusedBudgetKey || reliability.Children[0].Total != 4 {
+		t.Errorf("Reliability projects = %+v", reliability.Children)
+	}
+
+	byAssignee := groupReport(report, []string{GroupAssignee})
+	if len(byAssignee.Children) != 3 || byAssignee.Children[0].Name != "Ann" || byAssignee.Children[0].Used != 7 {
+		t.Errorf("assignees = %+v", byAssignee.Children)
+	}
+}
+
+func TestParseGrouping(t *testing.T) {
+	dims, err := parseGrouping("Month, initiative,project")
+	if err != nil || len(dims) != 3 || dims[0] != GroupMonth || dims[2] != GroupProject {
+		t.Errorf("parseGrouping() = %v, %v", dims, err)
+	}
+	for _, s := range []string{"", "month,month", "galaxy"} {
+		if _, err := parseGrouping(s); err == nil {
+			t.Errorf("parseGrouping(%q) succeeded, want error", s)
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// Unit is the unit the numbers of a report are expressed in.
+type Unit string
+
+const (
+	UnitPoints Unit = "points"
+	UnitHours  Unit = "hours"
+)
+
+// Short returns the abbreviation of the unit, e.g. "pts".
+func (u Unit) Short() string {
+	if u == UnitHours {
+		return "h"
+	}
+	return "pts"
+}
+
+// HoursConfig converts normalized points into hours.
+type HoursConfig struct {
+	// PerPoint is the number of hours per point; required for the hours unit.
+	PerPoint float64 `json:"per_point"`
+
+	// Teams override PerPoint for issues and calendar members of a team, by Linear team key.
+	Teams map[string]float64 `json:"teams"`
+
+	// People override PerPoint and Teams for issues assigned to a person and
+	// for their calendar capacity, by Linear user name.
+	People map[string]float64 `json:"people"`
+}
+
+// Describe summarizes the conversion, e.g. "1 point = 4 hours (OPS: 6, Ann: 5)".
+func (hc *HoursConfig) Describe() string {
+	var overrides []string
+	for _, key := range slices.Sorted(maps.Keys(hc.Teams)) {
+		overrides = append(overrides, fmt.Sprintf("%s: %s", key, formatPoints(hc.Teams[key])))
+	}
+	for _, name := range slices.Sorted(maps.Keys(hc.People)) {
+		overrides = append(overrides, fmt.Sprintf("%s: %s", name, formatPoints(hc.People[name])))
+	}
+	s := fmt.Sprintf("1 point = %s hours", formatPoints(hc.PerPoint))
+	if len(overrides) > 0 {
+		s += " (" + strings.Join(overrides, ", ") + ")"
+	}
+	return s
+}
+
+// resolveUnit validates a unit name, returning points if empty.
+func resolveUnit(name string) (Unit, error) {
+	switch unit := Unit(strings.ToLower(name)); unit {
+	case "", UnitPoints:
+		return UnitPoints, nil
+	case UnitHours:
+		if config.Hours.PerPoint <= 0 {
+			return "", fmt.Errorf("hours.per_point is not configured")
+		}
+		return unit, nil
+	default:
+		return "", fmt.Errorf("unknown unit %q", name)
+	}
+}
+
+// conversion scales quantities from normalized points into the unit of a report.
+type conversion struct {
+	hours *HoursConfig // nil for points
+}
+
+func newConversion(unit Unit) conversion {
+	if unit == UnitHours {
+		return conversion{hours: &config.Hours}
+	}
+	return conversion{}
+}
+
+// perPoint returns the factor for quantities not tied to a team or person,
+// like configured capacity and budgets.
+func (c conversion) perPoint() float64 {
+	if c.hours == nil {
+		return 1
+	}
+	return c.hours.PerPoint
+}
+
+// fromHours converts a quantity configured in hours, like a retainer, into
+// the unit of the report.
+func (c conversion) fromHours(hours float64) float64 {
+	if c.hours != nil {
+		return hours
+	}
+	return hours / config.Hours.PerPoint
+}
+
+// factor returns the factor for work of the given team and person, either of
+// which may be empty.
+func (c conversion) factor(teamKey, person string) float64 {
+	if c.hours == nil {
+		return 1
+	}
+	if f, ok := c.hours.People[person]; ok && person != "" {
+		return f
+	}
+	if f, ok := c.hours.Teams[teamKey]; ok && teamKey != "" {
+		return f
+	}
+	return c.hours.PerPoint
+}
+
+// convertIssue scales an issue's points by the factor of its team and assignee.
+func (c conversion) convertIssue(issue *IssueData) {
+	f := c.factor(issue.TeamKey, issue.Assignee)
+	issue.Points *= f
+	issue.Size *= f
+}
+
+// convertCapacity scales a capacity derivation, member by member if it comes
+// from the calendar.
+func (c conversion) convertCapacity(cd *CapacityDerivation) *CapacityDerivation {
+	if c.hours == nil {
+		return cd
+	}
+	if len(cd.Members) == 0 {
+		cd.Total *= c.perPoint()
+		cd.Deducted *= c.perPoint()
+		return cd
+	}
+	cd.Total = 0
+	for _, mc := range cd.Members {
+		f := c.factor(mc.Team, mc.Name)
+		mc.PointsPerDay *= f
+		mc.Points *= f
+		cd.Total += mc.Points
+	}
+	return cd
+}
+
+// Note explains what the numbers in this unit mean, empty for points.
+func (u Unit) Note() string {
+	if u != UnitHours {
+		return ""
+	}
+	return "All numbers in hours: " + config.Hours.Describe()
+}
+
+func (r *Report) IsHours() bool {
+	return r.Unit == UnitHours
+}
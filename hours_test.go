@@ -0,0 +1,100 @@
+package main
+
+import (
+	"encoding/json"
+	"maps"
+	"testing"
+	"time"
+
+	"github.com/prairiegroupinc/linearsummarybot/yearmonth"
+)
+
+func TestHoursUnit(t *testing.T) {
+	var issues []LinearIssue
+	err := json.Unmarshal([]byte(`[
+		{"identifier": "DEV-1", "estimate": 2, "dueDate": "2025-07-10", "team": {"key": "DEV"}},
+		{"identifier": "OPS-1", "estimate": 3, "dueDate": "2025-07-15", "team": {"key": "OPS"}},
+		{"identifier": "OPS-2", "estimate": 1, "dueDate": "2025-07-20", "team": {"key": "OPS"}, "assignee": {"name": "Ann"}}
+	]`), &issues)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	saved := config
+	defer func() { config = saved }()
+	config.ByMonth = map[yearmonth.YM]*MonthConfig{
+		yearmonth.Make(2025, 7): {Capacity: 20, Budget: map[string]BudgetAmount{"Reliability": {Points: 2}, "Last Minute": {Percent: 10}}},
+	}
+	config.Hours = HoursConfig{PerPoint: 4, Teams: map[string]float64{"OPS": 6}, People: map[string]float64{"Ann": 5}}
+
+	if _, err := resolveUnit("days"); err == nil {
+		t.Errorf("resolveUnit(days) should fail")
+	}
+	unit, err := resolveUnit("Hours")
+	if err != nil || unit != UnitHours {
+		t.Fatalf("resolveUnit(Hours) = %q, %v", unit, err)
+	}
+
+	tests := []struct {
+		unit                   Unit
+		capacity, used, budget float64
+	}{
+		{UnitPoints, 20, 6, 2 + 2},
+		{UnitHours, 80, 8 + 18 + 5, 8 + 8},
+	}
+	for _, tt := range tests {
+		report, err := computeReport(issues, nil, ReportOptions{Unit: tt.unit})
+		if err != nil {
+			t.Fatal(err)
+		}
+		md := report.Months[0]
+		budget := md.Budget["Reliability"] + md.Budget["Last Minute"]
+		if md.Capacity != tt.capacity || md.Used != tt.used || budget != tt.budget {
+			t.Errorf("%s: capacity = %v, used = %v, budget = %v, want %v, %v, %v", tt.unit, md.Capacity, md.Used, budget, tt.capacity, tt.used, tt.budget)
+		}
+	}
+
+	if got, want := config.Hours.Describe(), "1 point = 4 hours (OPS: 6, Ann: 5)"; got != want {
+		t.Errorf("Describe() = %q, want %q", got, want)
+	}
+}
+
+func TestHoursSpreadThreshold(t *testing.T) {
+	due := time.Now().UTC().AddDate(0, 3, 0).Format("2006-01-02")
+	var issues []LinearIssue
+	err := json.Unmarshal([]byte(`[
+		{"identifier": "DEV-1", "estimate": 2, "dueDate": "`+due+`", "team": {"key": "DEV"}},
+		{"identifier": "OPS-1", "estimate": 5, "dueDate": "`+due+`", "team": {"key": "OPS"}}
+	]`), &issues)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	saved := config
+	defer func() { config = saved }()
+	config.ByMonth = nil
+	config.MonthAttribution = MonthAttributionConfig{Mode: SpreadByWorkingDays, SpreadFlexFrom: 3}
+	config.Hours = HoursConfig{PerPoint: 4, Teams: map[string]float64{"OPS": 6}}
+
+	months := make(map[Unit]map[string]int) // identifier -> number of months
+	for _, unit := range []Unit{UnitPoints, UnitHours} {
+		report, err := computeReport(issues, nil, ReportOptions{Unit: unit})
+		if err != nil {
+			t.Fatal(err)
+		}
+		months[unit] = make(map[string]int)
+		for _, md := range report.Months {
+			for _, idata := range md.Initiatives {
+				for _, issue := range idata.Issues {
+					months[unit][issue.Identifier]++
+				}
+			}
+		}
+	}
+	if months[UnitPoints]["DEV-1"] != 1 || months[UnitPoints]["OPS-1"] < 2 {
+		t.Errorf("points: months per issue = %v, want DEV-1 in 1 and OPS-1 spread", months[UnitPoints])
+	}
+	if !maps.Equal(months[UnitPoints], months[UnitHours]) {
+		t.Errorf("months per issue = %v in points, %v in hours", months[UnitPoints], months[UnitHours])
+	}
+}
@@ -0,0 +1,193 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// ICSEvent is a VEVENT from an iCalendar file.
+type ICSEvent struct {
+	Summary   string
+	Start     time.Time
+	End       time.Time // exclusive
+	AllDay    bool
+	Attendees []ICSAttendee
+}
+
+type ICSAttendee struct {
+	Name  string // CN parameter, may be empty
+	Email string
+}
+
+// Days returns the dates (midnight UTC) covered by the event.
+func (ev *ICSEvent) Days() []time.Time {
+	var days []time.Time
+	year, month, day := ev.Start.Date()
+	for d := time.Date(year, month, day, 0, 0, 0, 0, time.UTC); d.Before(ev.End); d = d.AddDate(0, 0, 1) {
+		days = append(days, d)
+	}
+	return days
+}
+
+// loadICS loads events from a local .ics file or an http(s) URL.
+func loadICS(source string) ([]*ICSEvent, error) {
+	if strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://") {
+		return fetchICS(source)
+	}
+	return loadICSFile(source)
+}
+
+var icsHTTPClient = &http.Client{Timeout: 30 * time.Second}
+
+func fetchICS(url string) ([]*ICSEvent, error) {
+	resp, err := icsHTTPClient.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s: HTTP %s", url, resp.Status)
+	}
+	events, err := parseICS(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", url, err)
+	}
+	return events, nil
+}
+
+func loadICSFile(path string) ([]*ICSEvent, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	events, err := parseICS(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return events, nil
+}
+
+// parseICS parses events from an iCalendar (RFC 5545) stream. Only the
+// properties needed for holidays and time off are supported; recurrence
+// rules are ignored.
+func parseICS(r io.Reader) ([]*ICSEvent, error) {
+	var events []*ICSEvent
+	var cur *ICSEvent
+	lines, err := unfoldICSLines(r)
+	if err != nil {
+		return nil, err
+	}
+	for _, line := range lines {
+		name, params, value := splitICSLine(line)
+		switch {
+		case name == "BEGIN" && value == "VEVENT":
+			cur = &ICSEvent{}
+		case name == "END" && value == "VEVENT":
+			if cur == nil {
+				return nil, fmt.Errorf("unexpected END:VEVENT")
+			}
+			if cur.Start.IsZero() {
+				return nil, fmt.Errorf("event %q without DTSTART", cur.Summary)
+			}
+			if cur.End.IsZero() {
+				// RFC 5545: a date-only event without an end lasts one day
+				if cur.AllDay {
+					cur.End = cur.Start.AddDate(0, 0, 1)
+				} else {
+					cur.End = cur.Start
+				}
+			}
+			events = append(events, cur)
+			cur = nil
+		case cur == nil:
+			continue
+		case name == "SUMMARY":
+			cur.Summary = unescapeICSText(value)
+		case name == "ATTENDEE":
+			email, _ := strings.CutPrefix(strings.ToLower(value), "mailto:")
+			cur.Attendees = append(cur.Attendees, ICSAttendee{Name: params["CN"], Email: email})
+		case name == "DTSTART":
+			t, allDay, err := parseICSTime(params, value)
+			if err != nil {
+				return nil, fmt.Errorf("DTSTART: %w", err)
+			}
+			cur.Start, cur.AllDay = t, allDay
+		case name == "DTEND":
+			t, _, err := parseICSTime(params, value)
+			if err != nil {
+				return nil, fmt.Errorf("DTEND: %w", err)
+			}
+			cur.End = t
+		}
+	}
+	return events, nil
+}
+
+// maxICSLine limits the length of a physical line. Lines should be folded
+// at 75 bytes, but some calendars put long attendee lists or descriptions
+// on a single line.
+const maxICSLine = 16 << 20
+
+// unfoldICSLines splits the input into logical lines, joining continuation
+// lines that start with a space or a tab.
+func unfoldICSLines(r io.Reader) ([]string, error) {
+	var lines []string
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, maxICSLine)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if len(lines) > 0 && (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading calendar: %w", err)
+	}
+	return lines, nil
+}
+
+// splitICSLine splits "NAME;PARAM=X;PARAM2=Y:value" into its parts.
+func splitICSLine(line string) (name string, params map[string]string, value string) {
+	head, value, _ := strings.Cut(line, ":")
+	parts := strings.Split(head, ";")
+	name = strings.ToUpper(parts[0])
+	for _, p := range parts[1:] {
+		k, v, _ := strings.Cut(p, "=")
+		if params == nil {
+			params = make(map[string]string)
+		}
+		params[strings.ToUpper(k)] = strings.Trim(v, `"`)
+	}
+	return
+}
+
+func parseICSTime(params map[string]string, value string) (time.Time, bool, error) {
+	if params["VALUE"] == "DATE" || len(value) == 8 {
+		t, err := time.Parse("20060102", value)
+		return t, true, err
+	}
+	if strings.HasSuffix(value, "Z") {
+		t, err := time.Parse("20060102T150405Z", value)
+		return t, false, err
+	}
+	loc := time.UTC
+	if tzid := params["TZID"]; tzid != "" {
+		if l, err := time.LoadLocation(tzid); err == nil {
+			loc = l
+		}
+	}
+	t, err := time.ParseInLocation("20060102T150405", value, loc)
+	return t, false, err
+}
+
+func unescapeICSText(s string) string {
+	return strings.NewReplacer(`\n`, "\n", `\N`, "\n", `\,`, ",", `\;`, ";", `\\`, `\`).Replace(s)
+}
@@ -12,20 +12,50 @@ type LinearIssue struct {
 	Identifier string  `json:"identifier"`
 	Title      string  `json:"title"`
 	Estimate   *int    `json:"estimate"`
+	Priority   int     `json:"priority"`
+	SortOrder  float64 `json:"sortOrder"`
 	DueDate    *string `json:"dueDate"`
+	StartedAt  *string `json:"startedAt"`
 	URL        string  `json:"url"`
 	Labels     struct {
 		Nodes []struct {
-			Name string `json:"name"`
+			Name   string `json:"name"`
+			Parent *struct {
+				Name string `json:"name"`
+			} `json:"parent"`
 		} `json:"nodes"`
 	} `json:"labels"`
 	State struct {
 		Name string `json:"name"`
 	} `json:"state"`
+	Team struct {
+		Key  string `json:"key"`
+		Name string `json:"name"`
+	} `json:"team"`
+	Assignee *struct {
+		Name string `json:"name"`
+	} `json:"assignee"`
 	Cycle *struct {
 		StartsAt string `json:"startsAt"`
 		EndsAt   string `json:"endsAt"`
 	} `json:"cycle"`
+	ProjectMilestone *struct {
+		Id         string  `json:"id"`
+		Name       string  `json:"name"`
+		TargetDate *string `json:"targetDate"`
+	} `json:"projectMilestone"`
+	Relations struct {
+		Nodes []struct {
+			Type         string        `json:"type"`
+			RelatedIssue LinearRelated `json:"relatedIssue"`
+		} `json:"nodes"`
+	} `json:"relations"`
+	InverseRelations struct {
+		Nodes []struct {
+			Type  string        `json:"type"`
+			Issue LinearRelated `json:"issue"`
+		} `json:"nodes"`
+	} `json:"inverseRelations"`
 	Project *struct {
 		Name        string `json:"name"`
 		Initiatives struct {
@@ -36,6 +66,19 @@ type LinearIssue struct {
 	} `json:"project"`
 }
 
+// LinearRelated is the other side of an issue relation, which may be completed
+// and therefore not fetched on its own.
+type LinearRelated struct {
+	Identifier string `json:"identifier"`
+	Title      string `json:"title"`
+	URL        string `json:"url"`
+	Estimate   *int   `json:"estimate"`
+	State      struct {
+		Name string `json:"name"`
+		Type string `json:"type"`
+	} `json:"state"`
+}
+
 func fetchLinearIssues() ([]LinearIssue, error) {
 	var allIssues []LinearIssue
 	var after *string
@@ -64,7 +107,7 @@ func fetchPageOfLinearIssues(after *string) ([]LinearIssue, string, bool, error)
 		return nil, "", false, fmt.Errorf("please set LINEAR_API_KEY environment variable")
 	}
 
-	// We fetch non-completed issues (first 250), including project name and its first initiative
+	// We fetch non-completed issues (first 250), including project name and all of its initiatives
 	query := `
 	query($after: String) {
 	  issues(
@@ -79,23 +122,71 @@ func fetchPageOfLinearIssues(after *string) ([]LinearIssue, string, bool, error)
 	      identifier
 	      title
 	      estimate
+	      priority
+	      sortOrder
 	      dueDate
+	      startedAt
 	      url
 		  state {
 		    name
 		  }
+	      team {
+	        key
+	        name
+	      }
+	      assignee {
+	        name
+	      }
 		  labels(first: 50) {
 		    nodes {
 			  name
+			  parent {
+			    name
+			  }
 			}
 	      }
 	      cycle {
 	        startsAt
 	        endsAt
 	      }
+	      projectMilestone {
+	        id
+	        name
+	        targetDate
+	      }
+	      relations(first: 50) {
+	        nodes {
+	          type
+	          relatedIssue {
+	            identifier
+	            title
+	            url
+	            estimate
+	            state {
+	              name
+	              type
+	            }
+	          }
+	        }
+	      }
+	      inverseRelations(first: 50) {
+	        nodes {
+	          type
+	          issue {
+	            identifier
+	            title
+	            url
+	            estimate
+	            state {
+	              name
+	              type
+	            }
+	          }
+	        }
+	      }
 	      project {
 	        name
-	        initiatives(first: 1) {
+	        initiatives(first: 50) {
 	          nodes {
 	            name
 	          }
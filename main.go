@@ -4,7 +4,11 @@ import (
 	"flag"
 	"fmt"
 	"log"
+	"os"
+	"strings"
 	// The only allowed non-stdlib import, as provided.
+
+	"github.com/prairiegroupinc/linearsummarybot/yearmonth"
 )
 
 func main() {
@@ -14,14 +18,102 @@ func main() {
 
 	onceFlag := flag.Bool("once", false, "Run once on launch")
 	httpAddr := flag.String("http", "", "Listen address for HTTP server, e.g. :8080")
+	pivotFlag := flag.String("pivot", "", "Break months down by this label dimension instead of initiatives")
+	groupFlag := flag.String("group", "", "Group the report by these dimensions, e.g. month,initiative,project")
+	cutlineFlag := flag.Bool("cutline", false, "Print issues ranked against each month's capacity")
+	unitFlag := flag.String("unit", "", "Report numbers in points (default) or hours")
+	formatFlag := flag.String("format", "text", "Output format: "+strings.Join(formatNames(), ", "))
+	sheetFlag := flag.String("sheet", "issues", "Table to print with -format csv: issues or summary")
+	explainFlag := flag.String("explain", "", "Explain how an issue is scheduled, e.g. DEV-123")
+	chartFlag := flag.String("chart", "capacity", "Chart to draw with -format svg: capacity or initiatives")
+	monthsFlag := flag.Int("months", defaultChartMonths, "Number of months of the initiatives chart")
+	trendFlag := flag.String("trend", "", "Print the burn-up chart of a month as SVG, e.g. 2025-07")
+	emailFlag := flag.String("email", "", "Email the report to the recipients of these teams, e.g. OPS,DEV, or all")
+	templatesFlag := flag.String("templates", "", "Directory with templates overriding the built-in views, e.g. report.html or report.txt")
 	flag.Parse()
 
+	if err := loadTemplates(*templatesFlag); err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+
+	if *trendFlag != "" {
+		ym, err := yearmonth.Parse(*trendFlag)
+		if err != nil {
+			log.Fatalf("Error: %v", err)
+		}
+		snapshots, err := loadSnapshots(config.SnapshotsDir)
+		if err != nil {
+			log.Fatalf("Error: %v", err)
+		}
+		if err := writeBarChart(os.Stdout, burnUpChart(snapshots, ym)); err != nil {
+			log.Fatalf("Error: %v", err)
+		}
+		return
+	}
+
 	if *onceFlag {
-		rep, err := buildReport()
+		pivot, err := resolvePivot(*pivotFlag)
 		if err != nil {
 			log.Fatalf("Error: %v", err)
 		}
-		fmt.Println(rep)
+		format := lookupFormat(*formatFlag)
+		if format == nil {
+			log.Fatalf("Error: unknown format %q", *formatFlag)
+		}
+		unit, err := resolveUnit(*unitFlag)
+		if err != nil {
+			log.Fatalf("Error: %v", err)
+		}
+		var grouping []string
+		if *groupFlag != "" {
+			grouping, err = parseGrouping(*groupFlag)
+			if err != nil {
+				log.Fatalf("Error: %v", err)
+			}
+		}
+		opts := ReportOptions{Pivot: pivot, Unit: unit}
+		build, err := newReportBuilder()
+		if err != nil {
+			log.Fatalf("Error: %v", err)
+		}
+		rep, err := build(opts)
+		if err != nil {
+			log.Fatalf("Error: %v", err)
+		}
+		recordSnapshot(rep, opts)
+		if *emailFlag != "" {
+			teams, err := config.Email.resolveTeams(*emailFlag)
+			if err != nil {
+				log.Fatalf("Error: %v", err)
+			}
+			teamReport := func(team string) (*Report, error) {
+				teamOpts := opts
+				teamOpts.Filter.Team = team
+				return build(teamOpts)
+			}
+			if err := sendDigests(teamReport, &config.Email, teams); err != nil {
+				log.Fatalf("Error: %v", err)
+			}
+		} else if *explainFlag != "" {
+			e := rep.Explain(strings.ToUpper(*explainFlag))
+			if e == nil {
+				log.Fatalf("Error: issue %s not found", *explainFlag)
+			}
+			fmt.Print(formatExplanationText(e))
+		} else if *cutlineFlag {
+			fmt.Print(formatCutlineTextReport(rep))
+		} else {
+			err := format.Renderer.Render(os.Stdout, rep, RenderOptions{
+				Grouping:    grouping,
+				Sheet:       *sheetFlag,
+				Standalone:  true,
+				Chart:       *chartFlag,
+				ChartMonths: *monthsFlag,
+			})
+			if err != nil {
+				log.Fatalf("Error: %v", err)
+			}
+		}
 		return
 	}
 
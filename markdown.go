@@ -0,0 +1,125 @@
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"strings"
+)
+
+func init() {
+	registerFormat(&Format{Name: "md", Extension: "md", ContentType: "text/markdown; charset=utf-8", Priority: 1, Renderer: RenderFunc(func(w io.Writer, report *Report, opts RenderOptions) error {
+		_, err := io.WriteString(w, formatMarkdownReport(report))
+		return err
+	})})
+}
+
+// formatMarkdownReport renders the report as GitHub-flavored Markdown, with a
+// table per month and collapsible issue lists per initiative.
+func formatMarkdownReport(report *Report) string {
+	var sb strings.Builder
+
+	sb.WriteString("# Linear Report\n\n")
+	if note := report.Unit.Note(); note != "" {
+		fmt.Fprintf(&sb, "_%s_\n\n", mdEscape(note))
+	}
+
+	for _, md := range report.Months {
+		fmt.Fprintf(&sb, "## %s\n\n", md.Name)
+		if md.IsPast {
+			fmt.Fprintf(&sb, "Capacity: **%s**\n\n", formatPoints(md.Capacity))
+		} else {
+			fmt.Fprintf(&sb, "Remaining budget: **%s** of %s", formatPoints(md.RemainingBudget()), formatPoints(md.Capacity))
+			if md.IsOverCapacity() {
+				sb.WriteString(" (over capacity)")
+			}
+			sb.WriteString("\n\n")
+		}
+
+		fmt.Fprintf(&sb, "| %s | Total | Used | Fixed | Sched | Flex |\n", mdEscape(cmp.Or(report.Pivot, "Initiative")))
+		sb.WriteString("|---|--:|--:|--:|--:|--:|\n")
+		for _, idata := range md.SortedInitiatives {
+			fmt.Fprintf(&sb, "| %s | %s | %s | %s | %s | %s |\n", mdEscape(idata.Name), formatPoints(idata.Total), formatPoints(idata.Used), formatPoints(idata.Fixed), formatPoints(idata.Planned), formatPoints(idata.Flex))
+		}
+		fmt.Fprintf(&sb, "| **Total** | **%s** | **%s** | **%s** | **%s** | **%s** |\n\n", formatPoints(md.Total), formatPoints(md.Used), formatPoints(md.Fixed), formatPoints(md.Planned), formatPoints(md.Flex))
+
+		for _, idata := range md.SortedInitiatives {
+			if len(idata.Issues) == 0 {
+				continue
+			}
+			fmt.Fprintf(&sb, "<details>\n<summary>%s (%d issues)</summary>\n\n", htmlEscaper.Replace(idata.Name), len(idata.Issues))
+			for _, issue := range idata.Issues {
+				fmt.Fprintf(&sb, "- %s %s — %s, %s", mdIssueLink(issue.Identifier, issue.URL), mdEscape(issue.Title), formatPoints(issue.Points), issue.Schedule)
+				if issue.IsSplit() {
+					fmt.Fprintf(&sb, " (%s of %s)", formatPercent(issue.Share), formatPoints(issue.Size))
+				}
+				sb.WriteString("\n")
+			}
+			sb.WriteString("\n</details>\n\n")
+		}
+	}
+
+	if atRisk := report.AtRisk(); len(atRisk) > 0 {
+		sb.WriteString("## Commitments at risk\n\n")
+		sb.WriteString("| Issue | Due | Days left | Risk |\n")
+		sb.WriteString("|---|---|--:|--:|\n")
+		for _, ra := range atRisk {
+			fmt.Fprintf(&sb, "| %s %s | %s | %d | %s |\n", mdIssueLink(ra.Issue.Identifier, ra.Issue.URL), mdEscape(ra.Issue.Title), ra.Issue.DueDate.Format("2006-01-02"), ra.DaysLeft, formatPercent(ra.Score))
+		}
+		sb.WriteString("\n")
+	}
+
+	if len(report.Conflicts) > 0 {
+		sb.WriteString("## Dependency conflicts\n\n")
+		for _, c := range report.Conflicts {
+			fmt.Fprintf(&sb, "- %s\n", mdEscape(c.Description))
+		}
+		sb.WriteString("\n")
+	}
+
+	if len(report.Budgets) > 0 {
+		sb.WriteString("## Budget balance\n\n")
+		sb.WriteString("| Bucket | Month | Budget | Carried | Used | Balance |\n")
+		sb.WriteString("|---|---|--:|--:|--:|--:|\n")
+		for _, ledger := range report.Budgets {
+			for _, e := range ledger.Entries {
+				fmt.Fprintf(&sb, "| %s | %s | %s | %s | %s | %s |\n", mdEscape(ledger.Name), e.MonthName(), formatPoints(e.Budget), formatPoints(e.CarriedIn), formatPoints(e.Used), formatPoints(e.Balance()))
+			}
+		}
+		sb.WriteString("\n")
+	}
+
+	if len(report.Milestones) > 0 {
+		sb.WriteString("## Milestones\n\n")
+		sb.WriteString("| Milestone | Target | Weeks left | Points | Capacity | Status |\n")
+		sb.WriteString("|---|---|--:|--:|--:|---|\n")
+		for _, ms := range report.Milestones {
+			status := ""
+			if ms.IsOverdue() {
+				status = "**Overdue**"
+			} else if ms.IsAtRisk() {
+				status = "**At risk**"
+			}
+			fmt.Fprintf(&sb, "| %s / %s | %s | %s | %s | %s | %s |\n", mdEscape(ms.Project), mdEscape(ms.Name), ms.TargetDate.Format("2006-01-02"), formatPoints(max(ms.WeeksLeft, 0)), formatPoints(ms.Points), formatPoints(ms.CapacityLeft), status)
+		}
+		sb.WriteString("\n")
+	}
+
+	return sb.String()
+}
+
+// mdEscaper escapes characters that would break tables or be taken for markup.
+var mdEscaper = strings.NewReplacer(`\`, `\\`, `|`, `\|`, `*`, `\*`, `_`, `\_`, "`", "\\`", `[`, `\[`, `]`, `\]`, `<`, `&lt;`, `>`, `&gt;`)
+
+var htmlEscaper = strings.NewReplacer(`&`, `&amp;`, `<`, `&lt;`, `>`, `&gt;`)
+
+func mdEscape(s string) string {
+	return mdEscaper.Replace(s)
+}
+
+func mdIssueLink(identifier, url string) string {
+	if url == "" {
+		return identifier
+	}
+	return fmt.Sprintf("[%s](%s)", identifier, url)
+}
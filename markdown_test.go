@@ -0,0 +1,28 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFormatMarkdownReport(t *testing.T) {
+	report := newMockReport()
+	agMVP := report.Months[0].SortedInitiatives[0]
+	agMVP.Name = "AG | MVP"
+	agMVP.Issues[0].URL = "https://linear.app/x/issue/DEV-123"
+	agMVP.Issues[0].Title = "Implement feature_x"
+
+	got := formatMarkdownReport(report)
+	for _, want := range []string{
+		"## February 2025\n",
+		"| Initiative | Total | Used | Fixed | Sched | Flex |\n|---|--:|--:|--:|--:|--:|\n",
+		"| AG \\| MVP | 81 | 0 | 80 | 1 | 0 |\n",
+		"| **Total** | **82** | **0** | **81** | **1** | **0** |\n",
+		"<details>\n<summary>AG | MVP (1 issues)</summary>\n\n- [DEV-123](https://linear.app/x/issue/DEV-123) Implement feature\\_x — 5, Fixed\n\n</details>\n",
+		"- DEV-225 Refresh page after adding vendible to cart — 1, Fixed\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected Markdown to contain %q, got:\n%s", want, got)
+		}
+	}
+}
@@ -1,51 +1,124 @@
 package main
 
 import (
+	"fmt"
+	"slices"
 	"sort"
+	"strings"
+	"time"
 
 	"github.com/prairiegroupinc/linearsummarybot/yearmonth"
 )
 
 // Report represents a complete summary of all issues organized by month
 type Report struct {
-	Months []*MonthData
+	Months       []*MonthData
+	Milestones   []*MilestoneData
+	Budgets      []*BucketLedger
+	Risks        []*RiskAssessment // Fixed issues, riskiest first
+	Dependencies *DependencyGraph
+	Conflicts    []*DependencyConflict
+
+	// Pivot is the dimension months are broken down by, empty for initiatives.
+	Pivot string
+
+	// Unit is the unit of all numbers in the report.
+	Unit Unit
+
+	// Filter is the filter the months were narrowed down by.
+	Filter ReportFilter
+}
+
+// HasOrphans returns whether any month has issues without a project. Pivoted
+// reports have no orphans, since "Other" holds issues without a dimension value.
+func (r *Report) HasOrphans() bool {
+	if r.Pivot != "" {
+		return false
+	}
+	for _, md := range r.Months {
+		if other, ok := md.Initiatives["Other"]; ok && len(other.Issues) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// ReportOptions customizes how computeReport groups issues.
+type ReportOptions struct {
+	// Pivot is the name of a dimension to break months down by instead of initiatives.
+	Pivot string
+
+	// Unit converts all numbers into hours if UnitHours; points by default.
+	Unit Unit
+
+	// Filter drops parts of issues before they are added up into months.
+	Filter ReportFilter
 }
 
 type IssueData struct {
-	Identifier string
-	Title      string
-	Points     int
-	Schedule   Schedule
-	MonthName  string
-	YearMonth  yearmonth.YM
-	InitName   string // empty if orphaned
-	URL        string
-	Bucket     string
-	Labels     []string
-	Clients    []string
+	Identifier  string              `json:"identifier"`
+	Title       string              `json:"title"`
+	Points      float64             `json:"points"`   // points attributed to InitName, i.e. Size * Share
+	Size        float64             `json:"size"`     // full estimate of the issue in normalized points
+	Estimate    int                 `json:"estimate"` // full estimate of the issue on its team's scale
+	Share       float64             `json:"share"`    // fraction of the estimate attributed to InitName, 1 if not split
+	Schedule    Schedule            `json:"schedule"`
+	Priority    Priority            `json:"priority"`
+	SortOrder   float64             `json:"sort_order"`  // manual order within Linear views
+	DueDate     time.Time           `json:"due_date"`    // zero if none
+	StartedAt   time.Time           `json:"started_at"`  // zero if not started
+	CycleStart  time.Time           `json:"cycle_start"` // zero if not in a cycle
+	CycleEnd    time.Time           `json:"cycle_end"`
+	MonthName   string              `json:"month_name"`
+	YearMonth   yearmonth.YM        `json:"month"`
+	InitName    string              `json:"initiative,omitempty"`  // empty if orphaned
+	Project     string              `json:"project,omitempty"`     // empty if not in a project
+	Milestone   string              `json:"milestone,omitempty"`   // project milestone, empty if none
+	Initiatives []string            `json:"initiatives,omitempty"` // all initiatives of the issue's project
+	Team        string              `json:"team"`
+	TeamKey     string              `json:"team_key"`
+	Assignee    string              `json:"assignee,omitempty"`     // empty if unassigned
+	State       string              `json:"state"`                  // Linear workflow state, e.g. "In Progress"
+	SplitAcross []string            `json:"split_across,omitempty"` // months and groups the estimate is split across, if split
+	URL         string              `json:"url"`
+	Bucket      string              `json:"bucket,omitempty"`
+	Labels      []string            `json:"labels,omitempty"`
+	Clients     []string            `json:"clients,omitempty"`
+	Dimensions  map[string][]string `json:"dimensions,omitempty"` // dimension name -> values from labels
+}
+
+// IsSplit returns whether only a part of the issue is attributed to InitName.
+func (issue *IssueData) IsSplit() bool {
+	return issue.Share > 0 && issue.Share < 1
 }
 
 type MonthData struct {
 	Name        string
 	Key         yearmonth.YM
 	Initiatives map[string]*InitiativeData
+	Clients     map[string]*ClientData
 	Config      *MonthConfig
 	IsPast      bool
 
-	Capacity int
+	Capacity           float64
+	CapacityDerivation *CapacityDerivation
+	Budget             map[string]float64 // resolved budget per initiative
+	Absences           []*Absence
 
 	// Cached calculations
-	Fixed   int
-	Planned int
-	Flex    int
-	Used    int
-	Total   int
+	Fixed   float64
+	Planned float64
+	Flex    float64
+	Used    float64
+	Total   float64
 
 	// Cached sorting
 	SortedInitiatives []*InitiativeData
+	SortedClients     []*ClientData
+	Ranked            []*RankedIssue
 }
 
-func (md *MonthData) RemainingBudget() int {
+func (md *MonthData) RemainingBudget() float64 {
 	return md.Capacity - md.Total
 }
 
@@ -65,27 +138,158 @@ func (md *MonthData) LookupInitiative(name string) *InitiativeData {
 	return idata
 }
 
+func (md *MonthData) LookupClient(name string) *ClientData {
+	cdata, ok := md.Clients[name]
+	if !ok {
+		cdata = &ClientData{
+			Name:   name,
+			Issues: make([]*IssueData, 0),
+		}
+		md.Clients[name] = cdata
+	}
+	return cdata
+}
+
 type InitiativeData struct {
-	Name    string
-	Fixed   int
-	Planned int
-	Flex    int
-	Total   int
-	Used    int
-	Budget  int
-	Issues  []*IssueData
+	Name      string
+	Fixed     float64
+	Planned   float64
+	Flex      float64
+	Total     float64
+	Used      float64
+	Budget    float64
+	CarriedIn float64 // budget carried over from previous months, negative if overspent
+	Issues    []*IssueData
+}
+
+// EffectiveBudget returns the month's budget including carry-over.
+func (i *InitiativeData) EffectiveBudget() float64 {
+	return max(i.Budget+i.CarriedIn, 0)
 }
 
 // sortIssues sorts Issues by points (descending) and identifier (ascending)
 func (i *InitiativeData) sortIssues() {
-	sort.Slice(i.Issues, func(a, b int) bool {
-		if i.Issues[a].Points != i.Issues[b].Points {
-			return i.Issues[a].Points > i.Issues[b].Points // descending
+	sortIssues(i.Issues)
+}
+
+func sortIssues(issues []*IssueData) {
+	sort.Slice(issues, func(a, b int) bool {
+		if issues[a].Points != issues[b].Points {
+			return issues[a].Points > issues[b].Points // descending
 		}
-		return i.Issues[a].Identifier < i.Issues[b].Identifier // ascending
+		return issues[a].Identifier < issues[b].Identifier // ascending
 	})
 }
 
+// ClientData sums up the work done for a single client in a month. Issues
+// labeled with several clients are split evenly among them.
+//
+// Issues lists each issue once with the points attributed to the client, even
+// if the issue is split across several initiatives.
+type ClientData struct {
+	Name    string
+	Fixed   float64
+	Planned float64
+	Flex    float64
+	Used    float64
+	Budget  float64 // retainer, 0 if the client has none
+	Issues  []*IssueData
+}
+
+// addIssue attributes a fraction of an issue part to the client, merging parts
+// of the same issue into a single entry.
+func (cd *ClientData) addIssue(part *IssueData, fraction float64) {
+	for _, entry := range cd.Issues {
+		if entry.Identifier != part.Identifier {
+			continue
+		}
+		entry.Points += part.Points * fraction
+		entry.Share += part.Share * fraction
+		if !slices.Contains(strings.Split(entry.InitName, ", "), part.InitName) {
+			entry.InitName += ", " + part.InitName
+		}
+		return
+	}
+	entry := *part
+	entry.Points = part.Points * fraction
+	entry.Share = part.Share * fraction
+	cd.Issues = append(cd.Issues, &entry)
+}
+
+func (cd *ClientData) HasBudget() bool {
+	return cd.Budget > 0
+}
+
+// Balance is positive when the client is under-served and negative when over-served.
+func (cd *ClientData) Balance() float64 {
+	return cd.Budget - cd.Used
+}
+
+func (cd *ClientData) IsOverServed() bool {
+	return cd.HasBudget() && cd.Used > cd.Budget
+}
+
+func (cd *ClientData) IsUnderServed() bool {
+	return cd.HasBudget() && cd.Used < cd.Budget
+}
+
+// MilestoneData sums up the remaining work of a project milestone with a target date.
+type MilestoneData struct {
+	Project    string
+	Name       string
+	TargetDate time.Time
+	Points     float64
+	Issues     []*IssueData
+
+	// Time and capacity left from today until the target date
+	WeeksLeft    float64
+	CapacityLeft float64
+}
+
+func (ms *MilestoneData) IsOverdue() bool {
+	return ms.WeeksLeft <= 0
+}
+
+// IsAtRisk returns whether the remaining points exceed the whole capacity left
+// before the target date.
+func (ms *MilestoneData) IsAtRisk() bool {
+	return ms.Points > ms.CapacityLeft
+}
+
+// Priority is a Linear issue priority: 0 for none, 1 (urgent) to 4 (low).
+type Priority int
+
+const (
+	NoPriority Priority = iota
+	Urgent
+	High
+	Medium
+	Low
+)
+
+func (p Priority) String() string {
+	switch p {
+	case Urgent:
+		return "Urgent"
+	case High:
+		return "High"
+	case Medium:
+		return "Medium"
+	case Low:
+		return "Low"
+	default:
+		return "No priority"
+	}
+}
+
+// rank orders priorities from most to least important, with no priority last.
+func (p Priority) rank() int {
+	if p == NoPriority {
+		return int(Low) + 1
+	}
+	return int(p)
+}
+
 type Schedule int
 
 const (
@@ -94,3 +298,31 @@ const (
 	Planned
 	Flex
 )
+
+func (s Schedule) String() string {
+	switch s {
+	case Fixed:
+		return "Fixed"
+	case Planned:
+		return "Planned"
+	case Flex:
+		return "Flex"
+	default:
+		return "Unscheduled"
+	}
+}
+
+// MarshalText encodes the schedule by its name, e.g. in JSON reports.
+func (s Schedule) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+func (s *Schedule) UnmarshalText(data []byte) error {
+	for _, v := range []Schedule{Unscheduled, Fixed, Planned, Flex} {
+		if string(data) == v.String() {
+			*s = v
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown schedule %q", data)
+}
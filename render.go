@@ -0,0 +1,178 @@
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Renderer writes a report in a single format.
+type Renderer interface {
+	Render(w io.Writer, report *Report, opts RenderOptions) error
+}
+
+// RenderFunc adapts a function to a Renderer.
+type RenderFunc func(w io.Writer, report *Report, opts RenderOptions) error
+
+func (f RenderFunc) Render(w io.Writer, report *Report, opts RenderOptions) error {
+	return f(w, report, opts)
+}
+
+// RenderOptions are presentation settings that only some formats support.
+type RenderOptions struct {
+	// Grouping lists dimensions to group the report by; nil for the default layout.
+	Grouping []string
+
+	// Sheet selects the table of single-table formats: "issues" (default) or "summary".
+	Sheet string
+
+	// Standalone inlines the styles of HTML, so that it works without the server.
+	Standalone bool
+
+	// Chart selects the chart of image formats: "capacity" (default) or "initiatives".
+	Chart string
+
+	// ChartMonths is the number of months of the initiatives chart; 0 for the default.
+	ChartMonths int
+}
+
+// Format is an output format of the report, available to -format, ?format=,
+// the Accept header and /report.<Extension>.
+type Format struct {
+	Name        string
+	Extension   string
+	ContentType string
+	Attachment  bool // browsers should download rather than display it
+	Renderer    Renderer
+
+	// Priority picks among formats matching a wildcard like text/*; the
+	// highest wins, then the first registered.
+	Priority int
+}
+
+// formats lists registered formats in the order of registration.
+var formats []*Format
+
+// defaultFormat is served to clients that accept anything.
+const defaultFormat = "html"
+
+// registerFormat adds a format; formats register themselves from init functions.
+func registerFormat(f *Format) {
+	if lookupFormat(f.Name) != nil {
+		panic(fmt.Sprintf("format %q registered twice", f.Name))
+	}
+	formats = append(formats, f)
+}
+
+// lookupFormat finds a format by name or extension, or returns nil.
+func lookupFormat(name string) *Format {
+	name = strings.ToLower(name)
+	for _, f := range formats {
+		if f.Name == name || f.Extension == name {
+			return f
+		}
+	}
+	return nil
+}
+
+// formatNames returns the names of registered formats, e.g. for usage messages.
+func formatNames() []string {
+	var names []string
+	for _, f := range formats {
+		names = append(names, f.Name)
+	}
+	return names
+}
+
+// negotiateFormat picks the format most preferred by an Accept header, or
+// returns nil if none is acceptable. An empty header accepts anything.
+func negotiateFormat(accept string) *Format {
+	if strings.TrimSpace(accept) == "" {
+		return lookupFormat(defaultFormat)
+	}
+
+	type mediaRange struct {
+		typ string
+		q   float64
+	}
+	var ranges []mediaRange
+	for _, item := range strings.Split(accept, ",") {
+		typ, params, err := mime.ParseMediaType(strings.TrimSpace(item))
+		if err != nil {
+			continue
+		}
+		q := 1.0
+		if s, ok := params["q"]; ok {
+			if v, err := strconv.ParseFloat(s, 64); err == nil {
+				q = v
+			}
+		}
+		if q > 0 {
+			ranges = append(ranges, mediaRange{typ, q})
+		}
+	}
+	slices.SortStableFunc(ranges, func(a, b mediaRange) int {
+		return cmp.Compare(b.q, a.q)
+	})
+
+	for _, mr := range ranges {
+		if mr.typ == "*/*" {
+			return lookupFormat(defaultFormat)
+		}
+		var best *Format
+		for _, f := range formats {
+			typ, _, _ := mime.ParseMediaType(f.ContentType)
+			if typ == mr.typ {
+				return f
+			}
+			if strings.HasSuffix(mr.typ, "/*") && strings.HasPrefix(typ, strings.TrimSuffix(mr.typ, "*")) && (best == nil || f.Priority > best.Priority) {
+				best = f
+			}
+		}
+		if best != nil {
+			return best
+		}
+	}
+	return nil
+}
+
+// isNegotiated returns whether formatFromRequest picks the format from the
+// Accept header rather than from ?format= or the path.
+func isNegotiated(r *http.Request) bool {
+	return r.URL.Query().Get("format") == "" && !strings.HasPrefix(r.URL.Path, "/report.")
+}
+
+// formatFromRequest picks the format from ?format=, the /report.<ext> path or
+// the Accept header, in this order.
+func formatFromRequest(r *http.Request) (*Format, error) {
+	if name := r.URL.Query().Get("format"); name != "" {
+		if f := lookupFormat(name); f != nil {
+			return f, nil
+		}
+		return nil, fmt.Errorf("unknown format %q", name)
+	}
+	if ext, ok := strings.CutPrefix(r.URL.Path, "/report."); ok {
+		if f := lookupFormat(ext); f != nil {
+			return f, nil
+		}
+		return nil, fmt.Errorf("unknown format %q", ext)
+	}
+	if f := negotiateFormat(r.Header.Get("Accept")); f != nil {
+		return f, nil
+	}
+	return nil, fmt.Errorf("none of the formats %s is acceptable", strings.Join(formatNames(), ", "))
+}
+
+// writeReport sets the response headers of the format and renders the report.
+func writeReport(w http.ResponseWriter, f *Format, report *Report, opts RenderOptions) error {
+	w.Header().Set("Content-Type", f.ContentType)
+	if f.Attachment {
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="report.%s"`, f.Extension))
+	}
+	return f.Renderer.Render(w, report, opts)
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestFormatFromRequest(t *testing.T) {
+	tests := []struct {
+		url    string
+		accept string
+		want   string // empty for an error
+	}{
+		{"/", "", "html"},
+		{"/", "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8", "html"},
+		{"/", "*/*", "html"},
+		{"/report", "text/markdown", "md"},
+		{"/report", "application/json;q=0.5, text/plain", "text"},
+		{"/report", "image/png", ""},
+		{"/report", "text/*", "text"},
+		{"/report", "application/*", "json"},
+		{"/report", "text/csv;q=0.9, text/*", "text"},
+		{"/report.txt", "application/json", "text"},
+		{"/report.csv", "", "csv"},
+		{"/report.pdf", "", ""},
+		{"/report?format=xlsx", "text/html", "xlsx"},
+		{"/?format=MD", "", "md"},
+		{"/?format=pdf", "", ""},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest("GET", tt.url, nil)
+		if tt.accept != "" {
+			r.Header.Set("Accept", tt.accept)
+		}
+		f, err := formatFromRequest(r)
+		var got string
+		if err == nil {
+			got = f.Name
+		}
+		if got != tt.want {
+			t.Errorf("formatFromRequest(%s, Accept: %q) = %q, %v, want %q", tt.url, tt.accept, got, err, tt.want)
+		}
+	}
+}
+
+func TestIsNegotiated(t *testing.T) {
+	for url, want := range map[string]bool{
+		"/":                true,
+		"/report":          true,
+		"/report.csv":      false,
+		"/?format=md":      false,
+		"/report?unit=hrs": true,
+	} {
+		if got := isNegotiated(httptest.NewRequest("GET", url, nil)); got != want {
+			t.Errorf("isNegotiated(%s) = %v, want %v", url, got, want)
+		}
+	}
+}
@@ -0,0 +1,211 @@
+package main
+
+import (
+	"cmp"
+	"slices"
+	"time"
+
+	"github.com/prairiegroupinc/linearsummarybot/yearmonth"
+)
+
+// Risk factors of Fixed commitments, each valued from 0 (safe) to 1 (risky).
+const (
+	// RiskTime grows as the due date approaches; 1 when overdue.
+	RiskTime = "time"
+
+	// RiskNotStarted is 1 if the issue has not been started.
+	RiskNotStarted = "not_started"
+
+	// RiskSize grows with the normalized estimate; 1 from LargeEstimate points up.
+	RiskSize = "size"
+
+	// RiskAssigneeLoad is the assignee's points in the month relative to their
+	// capacity; 1 if unassigned.
+	RiskAssigneeLoad = "assignee_load"
+
+	// RiskOverCapacity grows with the month's overload; 1 from 20% over capacity up.
+	RiskOverCapacity = "over_capacity"
+)
+
+var riskFactors = []string{RiskTime, RiskNotStarted, RiskSize, RiskAssigneeLoad, RiskOverCapacity}
+
+// RiskConfig tunes the risk scoring of Fixed commitments.
+type RiskConfig struct {
+	// Weights of risk factors, all 1 by default.
+	Weights map[string]float64 `json:"weights"`
+
+	// HorizonDays is the number of working days before the due date from which
+	// the time factor starts growing; 20 by default.
+	HorizonDays int `json:"horizon_days"`
+
+	// LargeEstimate is the normalized estimate at which the size factor reaches 1; 8 by default.
+	LargeEstimate float64 `json:"large_estimate"`
+
+	// PersonCapacity is the monthly capacity of people not in the calendar; 40 by default.
+	PersonCapacity float64 `json:"person_capacity"`
+
+	// Threshold is the score (0 to 1) from which a commitment is at risk; 0.5 by default.
+	Threshold float64 `json:"threshold"`
+}
+
+func (rc *RiskConfig) weight(factor string) float64 {
+	if w, ok := rc.Weights[factor]; ok {
+		return w
+	}
+	return 1
+}
+
+func (rc *RiskConfig) horizonDays() int {
+	return cmp.Or(rc.HorizonDays, 20)
+}
+
+func (rc *RiskConfig) largeEstimate() float64 {
+	return cmp.Or(rc.LargeEstimate, 8)
+}
+
+func (rc *RiskConfig) personCapacity() float64 {
+	return cmp.Or(rc.PersonCapacity, 40)
+}
+
+func (rc *RiskConfig) threshold() float64 {
+	return cmp.Or(rc.Threshold, 0.5)
+}
+
+// RiskAssessment scores how endangered a Fixed commitment is.
+type RiskAssessment struct {
+	Issue    *IssueData
+	DaysLeft int     // working days until the due date, negative if overdue
+	Score    float64 // weighted average of factors, 0 to 1
+	Factors  []RiskFactor
+	AtRisk   bool
+}
+
+type RiskFactor struct {
+	Name   string
+	Value  float64
+	Weight float64
+}
+
+// AtRisk returns the assessments at or above the risk threshold, riskiest first.
+func (r *Report) AtRisk() []*RiskAssessment {
+	var result []*RiskAssessment
+	for _, ra := range r.Risks {
+		if ra.AtRisk {
+			result = append(result, ra)
+		}
+	}
+	return result
+}
+
+// assessRisks scores every Fixed issue of the report, riskiest first.
+func assessRisks(months []*MonthData, rc *RiskConfig, now time.Time) []*RiskAssessment {
+	type personMonth struct {
+		person string
+		ym     yearmonth.YM
+	}
+	load := make(map[personMonth]float64)
+	byID := make(map[string]*RiskAssessment)
+	issueMonths := make(map[string][]*MonthData)
+
+	var result []*RiskAssessment
+	for _, md := range months {
+		for _, idata := range md.SortedInitiatives {
+			for _, issue := range idata.Issues {
+				if issue.Assignee != "" {
+					load[personMonth{issue.Assignee, md.Key}] += issue.Points
+				}
+				if issue.Schedule != Fixed {
+					continue
+				}
+				if !slices.Contains(issueMonths[issue.Identifier], md) {
+					issueMonths[issue.Identifier] = append(issueMonths[issue.Identifier], md)
+				}
+				if byID[issue.Identifier] == nil {
+					ra := &RiskAssessment{Issue: issue}
+					byID[issue.Identifier] = ra
+					result = append(result, ra)
+				}
+			}
+		}
+	}
+
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	for _, ra := range result {
+		issue := ra.Issue
+		dueEnd := issue.DueDate.AddDate(0, 0, 1) // due date is inclusive
+		if dueEnd.After(today) {
+			ra.DaysLeft = countWorkingDays(today, dueEnd)
+		} else {
+			ra.DaysLeft = -countWorkingDays(dueEnd, today)
+		}
+
+		timeRisk := 1.0
+		if ra.DaysLeft > 0 {
+			timeRisk = clamp01(1 - float64(ra.DaysLeft)/float64(rc.horizonDays()))
+		}
+		notStarted := 0.0
+		if issue.StartedAt.IsZero() {
+			notStarted = 1
+		}
+		size := clamp01(issue.Size / rc.largeEstimate())
+
+		var assigneeLoad, overCapacity float64
+		for _, md := range issueMonths[issue.Identifier] {
+			if capacity := personCapacity(md, issue.Assignee, rc); issue.Assignee == "" || capacity <= 0 {
+				assigneeLoad = 1
+			} else {
+				assigneeLoad = max(assigneeLoad, clamp01(load[personMonth{issue.Assignee, md.Key}]/capacity))
+			}
+			if md.Capacity > 0 {
+				overCapacity = max(overCapacity, clamp01((md.Total/md.Capacity-1)*5))
+			}
+		}
+
+		values := map[string]float64{
+			RiskTime:         timeRisk,
+			RiskNotStarted:   notStarted,
+			RiskSize:         size,
+			RiskAssigneeLoad: assigneeLoad,
+			RiskOverCapacity: overCapacity,
+		}
+		var sum, weights float64
+		for _, name := range riskFactors {
+			w := rc.weight(name)
+			ra.Factors = append(ra.Factors, RiskFactor{Name: name, Value: values[name], Weight: w})
+			sum += w * values[name]
+			weights += w
+		}
+		if weights > 0 {
+			ra.Score = sum / weights
+		}
+		ra.AtRisk = ra.Score >= rc.threshold()
+	}
+
+	slices.SortFunc(result, func(a, b *RiskAssessment) int {
+		return cmp.Or(cmp.Compare(b.Score, a.Score), a.Issue.DueDate.Compare(b.Issue.DueDate), cmp.Compare(a.Issue.Identifier, b.Issue.Identifier))
+	})
+	return result
+}
+
+// personCapacity returns a person's capacity in a month from the calendar,
+// or the configured default if the calendar doesn't know them.
+func personCapacity(md *MonthData, person string, rc *RiskConfig) float64 {
+	var total float64
+	var found bool
+	if md.CapacityDerivation != nil {
+		for _, mc := range md.CapacityDerivation.Members {
+			if mc.Name == person {
+				total += mc.Points
+				found = true
+			}
+		}
+	}
+	if !found {
+		return rc.personCapacity()
+	}
+	return total
+}
+
+func clamp01(v float64) float64 {
+	return min(max(v, 0), 1)
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/prairiegroupinc/linearsummarybot/yearmonth"
+)
+
+func TestAssessRisks(t *testing.T) {
+	date := func(year, month, day int) time.Time {
+		return time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+	}
+	now := date(2025, 7, 7) // Monday
+
+	urgent := &IssueData{Identifier: "DEV-1", Estimate: 8, Size: 8, Points: 8, Schedule: Fixed, DueDate: date(2025, 7, 8)}
+	relaxed := &IssueData{Identifier: "DEV-2", Estimate: 2, Size: 2, Points: 2, Schedule: Fixed, DueDate: date(2025, 8, 29), Assignee: "Ann", StartedAt: date(2025, 7, 1)}
+	planned := &IssueData{Identifier: "DEV-3", Estimate: 18, Size: 18, Points: 18, Schedule: Planned, Assignee: "Ann"}
+	months := []*MonthData{
+		{
+			Key: yearmonth.Make(2025, 7), Capacity: 100, Total: 110,
+			SortedInitiatives: []*InitiativeData{{Issues: []*IssueData{urgent, planned}}},
+		},
+		{
+			Key: yearmonth.Make(2025, 8), Capacity: 100, Total: 50,
+			SortedInitiatives: []*InitiativeData{{Issues: []*IssueData{relaxed}}},
+		},
+	}
+	rc := &RiskConfig{PersonCapacity: 40}
+
+	risks := assessRisks(months, rc, now)
+	if len(risks) != 2 {
+		t.Fatalf("got %d assessments, want 2", len(risks))
+	}
+	if risks[0].Issue != urgent || risks[1].Issue != relaxed {
+		t.Fatalf("assessments not sorted by risk: %s, %s", risks[0].Issue.Identifier, risks[1].Issue.Identifier)
+	}
+
+	// DEV-1: 2 days left of 20, not started, large, unassigned, July 10% over capacity
+	if risks[0].DaysLeft != 2 {
+		t.Errorf("DaysLeft = %d, want 2", risks[0].DaysLeft)
+	}
+	if want := (0.9 + 1 + 1 + 1 + 0.5) / 5; math.Abs(risks[0].Score-want) > 1e-9 || !risks[0].AtRisk {
+		t.Errorf("DEV-1 score = %v, at risk = %v, want %v, true", risks[0].Score, risks[0].AtRisk, want)
+	}
+
+	// DEV-2: far away, started, small, Ann has 2 of 40 points in August
+	if want := (0 + 0 + 0.25 + 0.05 + 0) / 5; math.Abs(risks[1].Score-want) > 1e-9 || risks[1].AtRisk {
+		t.Errorf("DEV-2 score = %v, at risk = %v, want %v, false", risks[1].Score, risks[1].AtRisk, want)
+	}
+}
@@ -0,0 +1,225 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/prairiegroupinc/linearsummarybot/yearmonth"
+)
+
+// Snapshot records how much work each month had when a report was built,
+// so that trends can be plotted later. Numbers are in points.
+type Snapshot struct {
+	Date   time.Time        `json:"date"`
+	Months []*MonthSnapshot `json:"months"`
+}
+
+type MonthSnapshot struct {
+	Month    yearmonth.YM `json:"month"`
+	Capacity float64      `json:"capacity"`
+	Fixed    float64      `json:"fixed"`
+	Planned  float64      `json:"planned"`
+	Flex     float64      `json:"flex"`
+
+	// Initiatives holds the work of each initiative.
+	Initiatives map[string]float64 `json:"initiatives"`
+}
+
+func (ms *MonthSnapshot) Committed() float64 {
+	return ms.Fixed + ms.Planned + ms.Flex
+}
+
+// Month returns the snapshot of a month, or nil if it had no work yet.
+func (s *Snapshot) Month(ym yearmonth.YM) *MonthSnapshot {
+	for _, ms := range s.Months {
+		if ms.Month == ym {
+			return ms
+		}
+	}
+	return nil
+}
+
+// takeSnapshot records the current and future months of the report.
+func takeSnapshot(report *Report, now time.Time) *Snapshot {
+	s := &Snapshot{Date: now}
+	for _, md := range report.Months {
+		if md.IsPast {
+			continue
+		}
+		ms := &MonthSnapshot{
+			Month:       md.Key,
+			Capacity:    md.Capacity,
+			Fixed:       md.Fixed,
+			Planned:     md.Planned,
+			Flex:        md.Flex,
+			Initiatives: make(map[string]float64),
+		}
+		for _, idata := range md.SortedInitiatives {
+			if idata.Used > 0 {
+				ms.Initiatives[idata.Name] = idata.Used
+			}
+		}
+		s.Months = append(s.Months, ms)
+	}
+	return s
+}
+
+// saveSnapshot writes the snapshot into dir, replacing any earlier snapshot
+// of the same day, so that there is at most one file per day.
+func saveSnapshot(dir string, s *Snapshot) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	// Concurrent reports each write their own temporary file; the last rename wins.
+	f, err := os.CreateTemp(dir, ".snapshot-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	_, err = f.Write(data)
+	if err1 := f.Close(); err == nil {
+		err = err1
+	}
+	if err != nil {
+		return err
+	}
+	if err := os.Chmod(f.Name(), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), filepath.Join(dir, s.Date.Format("2006-01-02")+".json"))
+}
+
+// loadSnapshots reads all snapshots in dir, oldest first. A missing dir has
+// no snapshots.
+func loadSnapshots(dir string) ([]*Snapshot, error) {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	var snapshots []*Snapshot
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".json") {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, e.Name()))
+		if err != nil {
+			return nil, err
+		}
+		s := new(Snapshot)
+		if err := json.Unmarshal(data, s); err != nil {
+			return nil, fmt.Errorf("%s: %w", e.Name(), err)
+		}
+		snapshots = append(snapshots, s)
+	}
+	slices.SortFunc(snapshots, func(a, b *Snapshot) int {
+		return a.Date.Compare(b.Date)
+	})
+	return snapshots, nil
+}
+
+// recordSnapshot saves a snapshot of the report if snapshots_dir is set.
+// Only unfiltered reports by initiative in points are recorded, so that
+// snapshots stay comparable. Failures are logged rather than failing the report.
+func recordSnapshot(report *Report, opts ReportOptions) {
+	if config.SnapshotsDir == "" || opts.Pivot != "" || report.IsHours() || !opts.Filter.IsEmpty() {
+		return
+	}
+	if err := saveSnapshot(config.SnapshotsDir, takeSnapshot(report, time.Now())); err != nil {
+		log.Printf("saving snapshot: %v", err)
+	}
+}
+
+// recordSnapshotsDaily records a snapshot when the server starts and then
+// every day, so that web requests never write snapshots.
+func recordSnapshotsDaily() {
+	for {
+		report, err := buildReport(ReportOptions{})
+		if err != nil {
+			log.Printf("recording snapshot: %v", err)
+		} else {
+			recordSnapshot(report, ReportOptions{})
+		}
+		time.Sleep(24 * time.Hour)
+	}
+}
+
+// weeklySnapshots keeps the latest snapshot of each week, weeks starting on Monday.
+func weeklySnapshots(snapshots []*Snapshot) []*Snapshot {
+	var weekly []*Snapshot
+	var lastWeek time.Time
+	for _, s := range snapshots {
+		week := startOfWeek(s.Date)
+		if len(weekly) > 0 && week.Equal(lastWeek) {
+			weekly[len(weekly)-1] = s
+		} else {
+			weekly = append(weekly, s)
+		}
+		lastWeek = week
+	}
+	return weekly
+}
+
+func startOfWeek(t time.Time) time.Time {
+	y, m, d := t.Date()
+	day := time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+	return day.AddDate(0, 0, -(int(day.Weekday())+6)%7)
+}
+
+// burnUpChart shows how the work committed to a month grew, as seen each
+// week, stacked by initiative against the capacity known at the time. Weeks
+// in which the month had no work, or had already passed, are left out.
+func burnUpChart(snapshots []*Snapshot, ym yearmonth.YM) *BarChart {
+	weekly := weeklySnapshots(snapshots)
+
+	totals := make(map[string]float64)
+	for _, s := range weekly {
+		if ms := s.Month(ym); ms != nil {
+			for name, v := range ms.Initiatives {
+				totals[name] += v
+			}
+		}
+	}
+	stack := newNamedStack(totals)
+
+	chart := &BarChart{
+		Title:  ym.Time().Format("January 2006") + " as seen each week",
+		Unit:   UnitPoints,
+		Legend: stack.legend(),
+	}
+	for _, s := range weekly {
+		if ms := s.Month(ym); ms != nil {
+			chart.Bars = append(chart.Bars, &ChartBar{
+				Label:    startOfWeek(s.Date).Format("Jan 2"),
+				Segments: stack.segments(ms.Initiatives),
+				Limit:    ms.Capacity,
+			})
+		}
+	}
+	return chart
+}
+
+// trendMonths returns the current and future months of the latest snapshot.
+func trendMonths(snapshots []*Snapshot, now time.Time) []yearmonth.YM {
+	if len(snapshots) == 0 {
+		return nil
+	}
+	var months []yearmonth.YM
+	for _, ms := range snapshots[len(snapshots)-1].Months {
+		if ms.Month >= yearmonth.FromTime(now) {
+			months = append(months, ms.Month)
+		}
+	}
+	return months
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prairiegroupinc/linearsummarybot/yearmonth"
+)
+
+func TestSnapshots(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "snapshots")
+	jul := yearmonth.Make(2025, 7)
+	aug := yearmonth.Make(2025, 8)
+
+	snap := func(date string, capacity float64, initiatives map[string]float64) *Snapshot {
+		d, err := time.Parse(time.DateTime, date)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ms := &MonthSnapshot{Month: jul, Capacity: capacity, Initiatives: initiatives}
+		for _, v := range initiatives {
+			ms.Fixed += v
+		}
+		return &Snapshot{Date: d, Months: []*MonthSnapshot{ms, {Month: aug, Capacity: 100}}}
+	}
+	for _, s := range []*Snapshot{
+		snap("2025-04-15 10:00:00", 100, map[string]float64{"AG MVP": 10}),
+		snap("2025-04-16 10:00:00", 100, map[string]float64{"AG MVP": 20}),
+		snap("2025-04-16 18:00:00", 100, map[string]float64{"AG MVP": 25}), // replaces the morning
+		snap("2025-04-22 10:00:00", 120, map[string]float64{"AG MVP": 30, "Other": 5}),
+		snap("2025-05-01 10:00:00", 120, map[string]float64{"AG MVP": 40, "Other": 50}),
+	} {
+		if err := saveSnapshot(dir, s); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var files []string
+	for _, e := range entries {
+		files = append(files, e.Name())
+	}
+	if got, want := strings.Join(files, ","), "2025-04-15.json,2025-04-16.json,2025-04-22.json,2025-05-01.json"; got != want {
+		t.Errorf("files = %s, want %s", got, want)
+	}
+
+	snapshots, err := loadSnapshots(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(snapshots) != 4 || snapshots[1].Month(jul).Initiatives["AG MVP"] != 25 {
+		t.Fatalf("loaded %d snapshots, want 4 with the latest of each day", len(snapshots))
+	}
+
+	chart := burnUpChart(snapshots, jul)
+	var bars []string
+	for _, bar := range chart.Bars {
+		var segments []string
+		for _, s := range bar.Segments {
+			segments = append(segments, s.Name+"="+formatPoints(s.Value))
+		}
+		bars = append(bars, bar.Label+":"+strings.Join(segments, "+")+"/"+formatPoints(bar.Limit))
+	}
+	// AG MVP is stacked first in every week, having more work across all weeks.
+	want := "Apr 14:AG MVP=25/100; Apr 21:AG MVP=30+Other=5/120; Apr 28:AG MVP=40+Other=50/120"
+	if got := strings.Join(bars, "; "); got != want {
+		t.Errorf("bars = %s, want %s", got, want)
+	}
+
+	if got := trendMonths(snapshots, time.Date(2025, 7, 3, 0, 0, 0, 0, time.UTC)); len(got) != 2 || got[0] != jul || got[1] != aug {
+		t.Errorf("trendMonths in July = %v, want [2025-07 2025-08]", got)
+	}
+	if got := trendMonths(snapshots, time.Date(2025, 8, 3, 0, 0, 0, 0, time.UTC)); len(got) != 1 || got[0] != aug {
+		t.Errorf("trendMonths in August = %v, want [2025-08]", got)
+	}
+
+	missing, err := loadSnapshots(filepath.Join(dir, "missing"))
+	if err != nil || missing != nil {
+		t.Errorf("loadSnapshots of a missing dir = %v, %v", missing, err)
+	}
+}
+
+func TestStartOfWeek(t *testing.T) {
+	tests := []struct {
+		date, want string
+	}{
+		{"2025-04-14", "2025-04-14"}, // Monday
+		{"2025-04-16", "2025-04-14"},
+		{"2025-04-20", "2025-04-14"}, // Sunday
+		{"2025-04-21", "2025-04-21"},
+	}
+	for _, tt := range tests {
+		d, _ := time.Parse(time.DateOnly, tt.date)
+		if got := startOfWeek(d.Add(15 * time.Hour)).Format(time.DateOnly); got != tt.want {
+			t.Errorf("startOfWeek(%s) = %s, want %s", tt.date, got, tt.want)
+		}
+	}
+}
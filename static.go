@@ -0,0 +1,89 @@
+package main
+
+import (
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"html/template"
+	"io/fs"
+	"net/http"
+	"strings"
+)
+
+//go:embed static
+var staticFS embed.FS
+
+// staticHashes holds a short content hash of each static file, used both as
+// its ETag and as the ?v= cache buster of its URL.
+var staticHashes = hashStaticFiles()
+
+func hashStaticFiles() map[string]string {
+	hashes := make(map[string]string)
+	err := fs.WalkDir(staticFS, "static", func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		data, err := staticFS.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		sum := sha256.Sum256(data)
+		hashes[strings.TrimPrefix(path, "static/")] = hex.EncodeToString(sum[:6])
+		return nil
+	})
+	if err != nil {
+		panic(err)
+	}
+	return hashes
+}
+
+// staticURL returns the URL of a static file, versioned by its content so
+// that it can be cached forever.
+func staticURL(name string) string {
+	return "/static/" + name + "?v=" + staticHashes[name]
+}
+
+// serveStatic serves the embedded static files. Versioned URLs are cached
+// for a year; others are revalidated by ETag.
+func serveStatic() http.Handler {
+	sub, err := fs.Sub(staticFS, "static")
+	if err != nil {
+		panic(err)
+	}
+	files := http.StripPrefix("/static/", http.FileServerFS(sub))
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := strings.TrimPrefix(r.URL.Path, "/static/")
+		hash, ok := staticHashes[name]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("ETag", `"`+hash+`"`)
+		if r.URL.Query().Get("v") == hash {
+			w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+		} else {
+			w.Header().Set("Cache-Control", "no-cache")
+		}
+		files.ServeHTTP(w, r)
+	})
+}
+
+// inlineStyles returns the stylesheet for embedding into standalone pages.
+func inlineStyles() template.CSS {
+	data, err := staticFS.ReadFile("static/app.css")
+	if err != nil {
+		panic(err)
+	}
+	return template.CSS(data)
+}
+
+// isStandalone reports whether ?standalone=1 asks for a page that works
+// without the server, e.g. when saved or emailed.
+func isStandalone(r *http.Request) bool {
+	switch r.URL.Query().Get("standalone") {
+	case "", "0", "false":
+		return false
+	default:
+		return true
+	}
+}
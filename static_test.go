@@ -0,0 +1,70 @@
+package main
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestServeStatic(t *testing.T) {
+	hash := staticHashes["app.css"]
+	if hash == "" {
+		t.Fatal("app.css is not embedded")
+	}
+	tests := []struct {
+		url         string
+		ifNoneMatch string
+		status      int
+		cache       string
+	}{
+		{staticURL("app.css"), "", 200, "public, max-age=31536000, immutable"},
+		{"/static/app.css", "", 200, "no-cache"},
+		{"/static/app.css?v=stale", "", 200, "no-cache"},
+		{"/static/app.css", `"` + hash + `"`, 304, "no-cache"},
+		{"/static/missing.css", "", 404, ""},
+		{"/static/", "", 404, ""},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest("GET", tt.url, nil)
+		if tt.ifNoneMatch != "" {
+			r.Header.Set("If-None-Match", tt.ifNoneMatch)
+		}
+		w := httptest.NewRecorder()
+		serveStatic().ServeHTTP(w, r)
+		if w.Code != tt.status {
+			t.Errorf("%s: status %d, want %d", tt.url, w.Code, tt.status)
+		}
+		if got := w.Header().Get("Cache-Control"); got != tt.cache {
+			t.Errorf("%s: Cache-Control %q, want %q", tt.url, got, tt.cache)
+		}
+		if tt.status == 200 && !strings.HasPrefix(w.Header().Get("Content-Type"), "text/css") {
+			t.Errorf("%s: Content-Type %q", tt.url, w.Header().Get("Content-Type"))
+		}
+	}
+}
+
+func TestStandalonePage(t *testing.T) {
+	report := newMockReport()
+
+	var sb strings.Builder
+	if err := renderHTMLReport(&sb, report, RenderOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if html := sb.String(); !strings.Contains(html, `href="`+staticURL("app.css")+`"`) || strings.Contains(html, "<style>") {
+		t.Errorf("page should link to the stylesheet:\n%s", html[:min(len(html), 500)])
+	}
+
+	sb.Reset()
+	if err := renderHTMLReport(&sb, report, RenderOptions{Standalone: true}); err != nil {
+		t.Fatal(err)
+	}
+	html := sb.String()
+	if !strings.Contains(html, "<style>") || !strings.Contains(html, ".max-w-4xl { max-width: 56rem; }") {
+		t.Errorf("standalone page should inline the stylesheet")
+	}
+	for _, s := range []string{"/static/", "https://"} {
+		if strings.Contains(html, s) {
+			t.Errorf("standalone page references %q", s)
+		}
+	}
+}
@@ -0,0 +1,201 @@
+package main
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"io/fs"
+	"net/url"
+	"os"
+	"strings"
+	texttemplate "text/template"
+	"unicode/utf8"
+)
+
+// templateFuncs are available to HTML views and to text templates.
+var templateFuncs = map[string]any{
+	"points":        formatPoints,
+	"percent":       formatPercent,
+	"join":          strings.Join,
+	"upper":         strings.ToUpper,
+	"capacityColor": capacityColor,
+	"left":          alignLeft,
+	"right":         alignRight,
+	"query":         reportQuery,
+	"pathEscape":    url.PathEscape,
+	"capacityChart": func(report *Report) (template.HTML, error) {
+		return chartHTML(capacityChart(report))
+	},
+	"initiativeChart": func(report *Report, months int) (template.HTML, error) {
+		return chartHTML(initiativeChart(report, months))
+	},
+}
+
+// textViews are the text templates a templates directory may provide, by
+// format name. Without one, the format renders its built-in layout.
+var textViews = map[string]string{
+	"text": "report.txt",
+	"md":   "report.md",
+}
+
+// mustParseView parses an embedded view; embedded views are known to be valid.
+func mustParseView(name string) *template.Template {
+	return template.Must(parseView(embeddedViews(), name))
+}
+
+func parseView(fsys fs.FS, name string) (*template.Template, error) {
+	return template.New(name).Funcs(templateFuncs).ParseFS(fsys, name)
+}
+
+func embeddedViews() fs.FS {
+	fsys, err := fs.Sub(viewsFS, "views")
+	if err != nil {
+		panic(err)
+	}
+	return fsys
+}
+
+// loadTemplates replaces views with the files of the same name in dir, and
+// the built-in text and Markdown layouts with report.txt and report.md. Views
+// missing from dir keep their embedded defaults; an empty dir restores all
+// defaults.
+func loadTemplates(dir string) error {
+	embedded := embeddedViews()
+	var custom fs.FS
+	if dir != "" {
+		custom = os.DirFS(dir)
+	}
+	// pick returns the file system to load a view from, or nil if only the
+	// embedded views have it and it is not embedded either.
+	pick := func(name string) fs.FS {
+		if custom != nil {
+			if _, err := fs.Stat(custom, name); err == nil {
+				return custom
+			}
+		}
+		if _, err := fs.Stat(embedded, name); err == nil {
+			return embedded
+		}
+		return nil
+	}
+
+	views := []struct {
+		name string
+		tmpl **template.Template
+	}{
+		{"layout.html", &layoutTmpl},
+		{"report.html", &reportTmpl},
+		{"clients.html", &clientsTmpl},
+		{"groups.html", &groupsTmpl},
+		{"cutline.html", &cutlineTmpl},
+		{"explain.html", &explainTmpl},
+		{"trends.html", &trendsTmpl},
+		{"month.html", &monthTmpl},
+		{"digest.html", &digestTmpl},
+	}
+	parsed := make([]*template.Template, len(views))
+	for i, v := range views {
+		t, err := parseView(pick(v.name), v.name)
+		if err != nil {
+			return fmt.Errorf("templates: %w", err)
+		}
+		parsed[i] = t
+	}
+
+	renderers := make(map[*Format]Renderer)
+	for formatName, name := range textViews {
+		f := lookupFormat(formatName)
+		if f == nil {
+			continue
+		}
+		r := f.Renderer
+		if tr, ok := r.(*textTemplateRenderer); ok {
+			r = tr.fallback
+		}
+		if fsys := pick(name); fsys != nil {
+			t, err := texttemplate.New(name).Funcs(templateFuncs).ParseFS(fsys, name)
+			if err != nil {
+				return fmt.Errorf("templates: %w", err)
+			}
+			r = &textTemplateRenderer{tmpl: t, fallback: r}
+		}
+		renderers[f] = r
+	}
+
+	// Only swap once everything has parsed, so that a broken file leaves the
+	// previous templates in place.
+	for i, v := range views {
+		*v.tmpl = parsed[i]
+	}
+	for f, r := range renderers {
+		f.Renderer = r
+	}
+	return nil
+}
+
+// textTemplateRenderer renders the default layout of a text-based format with
+// a user-supplied template. Grouped reports use the built-in layout.
+type textTemplateRenderer struct {
+	tmpl     *texttemplate.Template
+	fallback Renderer
+}
+
+func (tr *textTemplateRenderer) Render(w io.Writer, report *Report, opts RenderOptions) error {
+	if opts.Grouping != nil {
+		return tr.fallback.Render(w, report, opts)
+	}
+	return tr.tmpl.Execute(w, ReportPageData{
+		Report:     report,
+		Dimensions: config.Dimensions,
+	})
+}
+
+// reportQuery returns the query string reproducing the report, with the given
+// pairs of keys and values replaced, for links that change a setting:
+// ?{{query .Report "unit" "hours"}}. Empty values remove a key.
+func reportQuery(report *Report, pairs ...string) (template.URL, error) {
+	if len(pairs)%2 != 0 {
+		return "", fmt.Errorf("query: odd number of arguments")
+	}
+	v := report.Query()
+	for i := 0; i < len(pairs); i += 2 {
+		if pairs[i+1] == "" {
+			v.Del(pairs[i])
+		} else {
+			v.Set(pairs[i], pairs[i+1])
+		}
+	}
+	return template.URL(v.Encode()), nil
+}
+
+// capacityColor names a color for how full a capacity is: "green" up to 90%,
+// "amber" up to 100% and "red" beyond. The names match Tailwind's palette, as
+// in text-{{capacityColor .Total .Capacity}}-700.
+func capacityColor(used, capacity float64) string {
+	switch {
+	case used > capacity:
+		return "red"
+	case used > 0.9*capacity:
+		return "amber"
+	default:
+		return "green"
+	}
+}
+
+// alignLeft left-aligns s in a column of the given width by padding it with
+// spaces on the right, for text templates.
+func alignLeft(width int, s string) string {
+	if n := utf8.RuneCountInString(s); n < width {
+		s += strings.Repeat(" ", width-n)
+	}
+	return s
+}
+
+// alignRight right-aligns s in a column of the given width by padding it with
+// spaces on the left, for text templates.
+func alignRight(width int, s string) string {
+	if n := utf8.RuneCountInString(s); n < width {
+		s = strings.Repeat(" ", width-n) + s
+	}
+	return s
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadTemplates(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"layout.html": `<main class="custom">{{.Content}}</main>`,
+		"report.txt":  "{{range .Report.Months}}{{.Name | upper | left 16}}|{{points .Total | right 5}} {{capacityColor .Total .Capacity}}\n{{end}}",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Cleanup(func() {
+		if err := loadTemplates(""); err != nil {
+			t.Fatal(err)
+		}
+	})
+	if err := loadTemplates(dir); err != nil {
+		t.Fatalf("loadTemplates: %v", err)
+	}
+
+	report := newMockReport()
+	report.Months[0].Capacity = 80
+
+	var sb strings.Builder
+	if err := lookupFormat("text").Renderer.Render(&sb, report, RenderOptions{}); err != nil {
+		t.Fatalf("text: %v", err)
+	}
+	if got, want := sb.String(), "FEBRUARY 2025   |   82 red\n"; got != want {
+		t.Errorf("text = %q, want %q", got, want)
+	}
+
+	sb.Reset()
+	if err := lookupFormat("text").Renderer.Render(&sb, report, RenderOptions{Grouping: []string{"month"}}); err != nil {
+		t.Fatalf("grouped text: %v", err)
+	}
+	if !strings.Contains(sb.String(), "FEBRUARY 2025") || strings.Contains(sb.String(), "|") {
+		t.Errorf("grouped text should use the built-in layout, got:\n%s", sb.String())
+	}
+
+	sb.Reset()
+	if err := renderHTMLReport(&sb, report, RenderOptions{}); err != nil {
+		t.Fatalf("html: %v", err)
+	}
+	if html := sb.String(); !strings.HasPrefix(html, `<main class="custom">`) || !strings.Contains(html, "DEV-123") {
+		t.Errorf("html should use the custom layout and the embedded report view, got:\n%s", html)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "report.html"), []byte("{{.Broken"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := loadTemplates(dir); err == nil {
+		t.Error("loadTemplates accepted a broken template")
+	}
+
+	if err := loadTemplates(""); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := lookupFormat("text").Renderer.(*textTemplateRenderer); ok {
+		t.Error("loadTemplates(\"\") kept the custom text template")
+	}
+}
+
+func TestCapacityColor(t *testing.T) {
+	tests := []struct {
+		used, capacity float64
+		want           string
+	}{
+		{0, 0, "green"},
+		{50, 100, "green"},
+		{90, 100, "green"},
+		{95, 100, "amber"},
+		{100, 100, "amber"},
+		{101, 100, "red"},
+		{1, 0, "red"},
+	}
+	for _, tt := range tests {
+		if got := capacityColor(tt.used, tt.capacity); got != tt.want {
+			t.Errorf("capacityColor(%v, %v) = %q, want %q", tt.used, tt.capacity, got, tt.want)
+		}
+	}
+}
@@ -2,39 +2,50 @@ package main
 
 import (
 	"fmt"
+	"io"
 	"strings"
 )
 
+func init() {
+	registerFormat(&Format{Name: "text", Extension: "txt", ContentType: "text/plain; charset=utf-8", Renderer: RenderFunc(renderTextReport), Priority: 3})
+}
+
+func renderTextReport(w io.Writer, report *Report, opts RenderOptions) error {
+	var err error
+	if opts.Grouping != nil {
+		_, err = io.WriteString(w, formatGroupedTextReport(groupReport(report, opts.Grouping)))
+	} else {
+		_, err = io.WriteString(w, formatTextReport(report))
+	}
+	return err
+}
+
 func formatTextReport(report *Report) string {
 	var sb strings.Builder
 
+	if note := report.Unit.Note(); note != "" {
+		fmt.Fprintf(&sb, "%s\n\n", note)
+	}
+
 	// Print header
-	fmt.Fprintf(&sb, "%-45s %5s %5s %5s %5s\n", "", "Total", "Fixed", "Sched", "Flex")
+	fmt.Fprintf(&sb, "%-45s %5s %5s %5s %5s\n", strings.ToUpper(report.Pivot), "Total", "Fixed", "Sched", "Flex")
 	sb.WriteString("---------------------------------------------------------------------\n")
 
 	// Print each month
 	for _, md := range report.Months {
 		// Print month row
-		fmt.Fprintf(&sb, "%-45s %5d %5d %5d %5d\n", strings.ToUpper(md.Name), md.Total, md.Fixed, md.Planned, md.Flex)
+		fmt.Fprintf(&sb, "%-45s %5s %5s %5s %5s\n", strings.ToUpper(md.Name), formatPoints(md.Total), formatPoints(md.Fixed), formatPoints(md.Planned), formatPoints(md.Flex))
 
 		// Print each initiative
 		for _, idata := range md.SortedInitiatives {
-			fmt.Fprintf(&sb, "%-45s %5d %5d %5d %5d\n", idata.Name, idata.Total, idata.Fixed, idata.Planned, idata.Flex)
+			fmt.Fprintf(&sb, "%-45s %5s %5s %5s %5s\n", idata.Name, formatPoints(idata.Total), formatPoints(idata.Fixed), formatPoints(idata.Planned), formatPoints(idata.Flex))
 		}
 
 		sb.WriteString("---------------------------------------------------------------------\n")
 	}
 
 	// Print orphaned issues if any exist
-	hasOrphans := false
-	for _, md := range report.Months {
-		if other, ok := md.Initiatives["Other"]; ok && len(other.Issues) > 0 {
-			hasOrphans = true
-			break
-		}
-	}
-
-	if hasOrphans {
+	if report.HasOrphans() {
 		sb.WriteString("\n\nIssues without a project:\n")
 		for _, md := range report.Months {
 			other, ok := md.Initiatives["Other"]
@@ -44,25 +55,148 @@ func formatTextReport(report *Report) string {
 			fmt.Fprintf(&sb, "\n%s:\n", strings.ToUpper(md.Name))
 			// Print sorted issues
 			for _, issue := range other.Issues {
-				fmt.Fprintf(&sb, "  [%2d] %s: %s\n", issue.Points, issue.Identifier, issue.Title)
+				fmt.Fprintf(&sb, "  [%2s] %s: %s\n", formatPoints(issue.Points), issue.Identifier, issue.Title)
+			}
+		}
+		sb.WriteString("---------------------------------------------------------------------\n")
+	}
+
+	// Print commitments at risk if any exist
+	if atRisk := report.AtRisk(); len(atRisk) > 0 {
+		sb.WriteString("\n\nCommitments at risk:\n")
+		for _, ra := range atRisk {
+			fmt.Fprintf(&sb, "  %4s [%2s] %s: %s (due %s, %d working days left)\n", formatPercent(ra.Score), formatPoints(ra.Issue.Size), ra.Issue.Identifier, ra.Issue.Title, ra.Issue.DueDate.Format("2006-01-02"), ra.DaysLeft)
+		}
+		sb.WriteString("---------------------------------------------------------------------\n")
+	}
+
+	// Print dependency conflicts if any exist
+	if len(report.Conflicts) > 0 {
+		sb.WriteString("\n\nDependency conflicts:\n")
+		for _, c := range report.Conflicts {
+			fmt.Fprintf(&sb, "  %s\n", c.Description)
+		}
+		sb.WriteString("---------------------------------------------------------------------\n")
+	}
+
+	// Print budget balances if any exist
+	if len(report.Budgets) > 0 {
+		sb.WriteString("\n\nBudget balance:\n")
+		fmt.Fprintf(&sb, "%-45s %5s %5s %5s %5s\n", "", "Bdgt", "Carry", "Used", "Bal")
+		for _, ledger := range report.Budgets {
+			fmt.Fprintf(&sb, "%s (carry-over: %s)\n", ledger.Name, ledger.Mode)
+			for _, e := range ledger.Entries {
+				fmt.Fprintf(&sb, "  %-43s %5s %5s %5s %5s\n", e.MonthName(), formatPoints(e.Budget), formatPoints(e.CarriedIn), formatPoints(e.Used), formatPoints(e.Balance()))
 			}
 		}
 		sb.WriteString("---------------------------------------------------------------------\n")
 	}
 
+	// Print milestones if any exist
+	if len(report.Milestones) > 0 {
+		sb.WriteString("\n\nMilestones:\n")
+		fmt.Fprintf(&sb, "%-45s %10s %5s %5s %5s\n", "", "Target", "Weeks", "Pts", "Cap")
+		for _, ms := range report.Milestones {
+			status := ""
+			if ms.IsOverdue() {
+				status = "  OVERDUE"
+			} else if ms.IsAtRisk() {
+				status = "  AT RISK"
+			}
+			fmt.Fprintf(&sb, "%-45s %10s %5s %5s %5s%s\n", ms.Project+" / "+ms.Name, ms.TargetDate.Format("2006-01-02"), formatPoints(max(ms.WeeksLeft, 0)), formatPoints(ms.Points), formatPoints(ms.CapacityLeft), status)
+		}
+		sb.WriteString("---------------------------------------------------------------------\n")
+	}
+
+	return sb.String()
+}
+
+func formatCutlineTextReport(report *Report) string {
+	var sb strings.Builder
+
+	if note := report.Unit.Note(); note != "" {
+		fmt.Fprintf(&sb, "%s\n\n", note)
+	}
+
+	for _, md := range report.Months {
+		if md.IsPast {
+			continue
+		}
+		fmt.Fprintf(&sb, "%s (capacity for issues: %s of %s)\n", strings.ToUpper(md.Name), formatPoints(md.IssueCapacity()), formatPoints(md.Capacity))
+		sb.WriteString("---------------------------------------------------------------------\n")
+		for i, r := range md.Ranked {
+			if i == md.CutlineIndex() {
+				sb.WriteString("=============================== CAPACITY LINE =======================\n")
+			}
+			fmt.Fprintf(&sb, "  [%2s] %-9s %-7s %-11s %5s  %s\n", formatPoints(r.Points), r.Issue.Identifier, r.Issue.Schedule, r.Issue.Priority, formatPoints(r.Cumulative), r.Issue.Title)
+		}
+		sb.WriteString("\n")
+	}
+
+	return sb.String()
+}
+
+func formatGroupedTextReport(root *GroupNode) string {
+	var sb strings.Builder
+
+	if note := root.Unit.Note(); note != "" {
+		fmt.Fprintf(&sb, "%s\n\n", note)
+	}
+
+	// Print header
+	fmt.Fprintf(&sb, "%-45s %5s %5s %5s %5s %5s %6s\n", "", "Total", "Used", "Fixed", "Sched", "Flex", "Budget")
+	sb.WriteString("----------------------------------------------------------------------------------\n")
+
+	var printNode func(n *GroupNode)
+	printNode = func(n *GroupNode) {
+		name := strings.Repeat("  ", n.Depth-1) + n.Name
+		if n.Depth == 1 {
+			name = strings.ToUpper(name)
+		}
+		fmt.Fprintf(&sb, "%-45s %5s %5s %5s %5s %5s %6s\n", name, formatPoints(n.Total), formatPoints(n.Used), formatPoints(n.Fixed), formatPoints(n.Planned), formatPoints(n.Flex), formatPoints(n.Budget))
+		for _, c := range n.Children {
+			printNode(c)
+		}
+		if n.Depth == 1 {
+			sb.WriteString("----------------------------------------------------------------------------------\n")
+		}
+	}
+	for _, c := range root.Children {
+		printNode(c)
+	}
+
+	// Print grand total
+	fmt.Fprintf(&sb, "%-45s %5s %5s %5s %5s %5s %6s\n", "TOTAL", formatPoints(root.Total), formatPoints(root.Used), formatPoints(root.Fixed), formatPoints(root.Planned), formatPoints(root.Flex), formatPoints(root.Budget))
+
 	return sb.String()
 }
 
-func buildReport() (*Report, error) {
+func buildReport(opts ReportOptions) (*Report, error) {
+	build, err := newReportBuilder()
+	if err != nil {
+		return nil, err
+	}
+	return build(opts)
+}
+
+// newReportBuilder fetches issues and absences once for building several
+// reports from them.
+func newReportBuilder() (func(ReportOptions) (*Report, error), error) {
 	issues, err := fetchLinearIssues()
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch issues: %v", err)
 	}
 
-	report, err := computeReport(issues)
+	absences, err := config.Calendar.loadAbsences()
 	if err != nil {
-		return nil, fmt.Errorf("failed to compute report: %v", err)
+		return nil, err
 	}
 
-	return report, nil
+	return func(opts ReportOptions) (*Report, error) {
+		report, err := computeReport(issues, absences, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to compute report: %v", err)
+		}
+		return report, nil
+	}, nil
 }
@@ -2,6 +2,8 @@ package main
 
 import (
 	"fmt"
+	"math"
+	"strconv"
 	"time"
 )
 
@@ -23,3 +25,13 @@ func must[T any](v T, err error) T {
 	}
 	return v
 }
+
+// formatPoints formats points with at most one decimal digit, omitting it for whole numbers.
+func formatPoints(v float64) string {
+	return strconv.FormatFloat(math.Round(v*10)/10, 'f', -1, 64)
+}
+
+// formatPercent formats a fraction (0.25) as a whole percentage (25%).
+func formatPercent(v float64) string {
+	return strconv.FormatFloat(math.Round(v*100), 'f', -1, 64) + "%"
+}
@@ -1,93 +1,374 @@
 package main
 
 import (
+	"cmp"
 	"embed"
+	"encoding/json"
 	"fmt"
 	"html/template"
+	"io"
 	"log"
 	"net/http"
+	"strconv"
 	"strings"
+	"time"
+
+	"github.com/prairiegroupinc/linearsummarybot/yearmonth"
 )
 
-//go:embed views/layout.html views/report.html
+//go:embed views/layout.html views/report.html views/clients.html views/groups.html views/cutline.html views/explain.html views/trends.html views/month.html views/digest.html
 var viewsFS embed.FS
 
+func init() {
+	registerFormat(&Format{Name: "html", Extension: "html", ContentType: "text/html; charset=utf-8", Renderer: RenderFunc(renderHTMLReport), Priority: 2})
+	registerFormat(&Format{Name: "json", Extension: "json", ContentType: "application/json; charset=utf-8", Renderer: RenderFunc(renderJSONReport)})
+}
+
 var (
-	layoutTmpl = template.Must(template.ParseFS(viewsFS, "views/layout.html"))
-	reportTmpl = template.Must(template.ParseFS(viewsFS, "views/report.html"))
+	layoutTmpl  = mustParseView("layout.html")
+	reportTmpl  = mustParseView("report.html")
+	clientsTmpl = mustParseView("clients.html")
+	groupsTmpl  = mustParseView("groups.html")
+	cutlineTmpl = mustParseView("cutline.html")
+	explainTmpl = mustParseView("explain.html")
+	trendsTmpl  = mustParseView("trends.html")
+	monthTmpl   = mustParseView("month.html")
+	digestTmpl  = mustParseView("digest.html")
 )
 
 type PageData struct {
 	Title   string
 	Content template.HTML
+
+	// Styles is the inlined stylesheet of standalone pages; otherwise the
+	// page links to StylesURL.
+	Styles    template.CSS
+	StylesURL string
 }
 
 type ReportPageData struct {
 	Report     *Report
-	HasOrphans bool
+	Dimensions []*DimensionConfig
+}
+
+type TrendsPageData struct {
+	Enabled bool // whether snapshots_dir is set
+	Trends  []*Trend
+}
+
+// Trend is the burn-up chart of a month.
+type Trend struct {
+	Month  yearmonth.YM
+	Name   string
+	Latest *MonthSnapshot
+	Chart  template.HTML
+}
+
+type GroupedPageData struct {
+	Grouping []string
+	Root     *GroupNode
 }
 
 func startWeb(listenAddr string) {
-	http.HandleFunc("/", serveHTMLReport)
-	http.HandleFunc("/report.txt", serveTextReport)
+	http.HandleFunc("/", serveReport)
+	http.HandleFunc("/report", serveReport)
+	for _, f := range formats {
+		http.HandleFunc("/report."+f.Extension, serveReport)
+	}
+	http.HandleFunc("/clients", serveClientsReport)
+	http.HandleFunc("/cutline", serveCutlineReport)
+	http.HandleFunc("/explain/{identifier}", serveExplanation)
+	http.HandleFunc("/month/{month}", serveDrillDown)
+	http.HandleFunc("/month/{month}/initiative/{name}", serveDrillDown)
+	http.HandleFunc("/trends", serveTrends)
+	http.HandleFunc("/trends/{file}", serveTrendChart)
+	http.Handle("/static/", serveStatic())
+	if config.SnapshotsDir != "" {
+		go recordSnapshotsDaily()
+	}
 	log.Printf("Listening on %s", listenAddr)
 	log.Fatal(http.ListenAndServe(listenAddr, nil))
 }
 
-func serveTextReport(w http.ResponseWriter, r *http.Request) {
-	report, err := buildReport()
+func reportOptionsFromRequest(r *http.Request) (ReportOptions, error) {
+	pivot, err := resolvePivot(r.URL.Query().Get("pivot"))
+	if err != nil {
+		return ReportOptions{}, err
+	}
+	unit, err := resolveUnit(r.URL.Query().Get("unit"))
+	if err != nil {
+		return ReportOptions{}, err
+	}
+	filter, err := parseFilter(r.URL.Query())
+	if err != nil {
+		return ReportOptions{}, err
+	}
+	return ReportOptions{Pivot: pivot, Unit: unit, Filter: filter}, nil
+}
+
+// groupingFromRequest returns the dimensions from ?group=, or nil if not specified.
+func groupingFromRequest(r *http.Request) ([]string, error) {
+	if !r.URL.Query().Has("group") {
+		return nil, nil
+	}
+	return parseGrouping(r.URL.Query().Get("group"))
+}
+
+// serveReport renders the report in the format picked by formatFromRequest.
+func serveReport(w http.ResponseWriter, r *http.Request) {
+	if isNegotiated(r) {
+		w.Header().Add("Vary", "Accept")
+	}
+	f, err := formatFromRequest(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotAcceptable)
+		return
+	}
+	opts, err := reportOptionsFromRequest(r)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+	grouping, err := groupingFromRequest(r)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+	if err := validateChart(r.URL.Query().Get("chart")); err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+	var chartMonths int
+	if s := r.URL.Query().Get("months"); s != "" {
+		chartMonths, err = strconv.Atoi(s)
+		if err != nil || chartMonths <= 0 {
+			http.Error(w, fmt.Sprintf("invalid months %q", s), 400)
+			return
+		}
+	}
+	report, err := buildReport(opts)
+	if err != nil {
+		http.Error(w, err.Error(), 500)
+		return
+	}
+
+	err = writeReport(w, f, report, RenderOptions{
+		Grouping:    grouping,
+		Sheet:       r.URL.Query().Get("sheet"),
+		Standalone:  isStandalone(r),
+		Chart:       r.URL.Query().Get("chart"),
+		ChartMonths: chartMonths,
+	})
+	if err != nil {
+		log.Printf("rendering %s report: %v", f.Name, err)
+	}
+}
+
+func serveClientsReport(w http.ResponseWriter, r *http.Request) {
+	opts, err := reportOptionsFromRequest(r)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+	report, err := buildReport(opts)
+	if err != nil {
+		http.Error(w, err.Error(), 500)
+		return
+	}
+
+	err = renderPage(w, r, "Clients", clientsTmpl, ReportPageData{Report: report})
+	if err != nil {
+		http.Error(w, err.Error(), 500)
+		return
+	}
+}
+
+func serveCutlineReport(w http.ResponseWriter, r *http.Request) {
+	opts, err := reportOptionsFromRequest(r)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+	report, err := buildReport(ReportOptions{Unit: opts.Unit}) // ranking ignores pivots and filters
+	if err != nil {
+		http.Error(w, err.Error(), 500)
+		return
+	}
+
+	err = renderPage(w, r, "Capacity Line", cutlineTmpl, ReportPageData{Report: report})
+	if err != nil {
+		http.Error(w, err.Error(), 500)
+		return
+	}
+}
+
+func serveExplanation(w http.ResponseWriter, r *http.Request) {
+	opts, err := reportOptionsFromRequest(r)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+	report, err := buildReport(opts)
+	if err != nil {
+		http.Error(w, err.Error(), 500)
+		return
+	}
+
+	identifier := strings.ToUpper(r.PathValue("identifier"))
+	e := report.Explain(identifier)
+	if e == nil {
+		http.Error(w, fmt.Sprintf("issue %s not found", identifier), 404)
+		return
+	}
+
+	err = renderPage(w, r, identifier, explainTmpl, e)
 	if err != nil {
 		http.Error(w, err.Error(), 500)
 		return
 	}
-	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
-	fmt.Fprint(w, formatTextReport(report))
 }
 
-func serveHTMLReport(w http.ResponseWriter, r *http.Request) {
-	// Get the report
-	report, err := buildReport()
+// serveDrillDown serves the issues of a month, or of an initiative of a month.
+func serveDrillDown(w http.ResponseWriter, r *http.Request) {
+	ym, err := yearmonth.Parse(r.PathValue("month"))
+	if err != nil {
+		http.Error(w, fmt.Sprintf("invalid month %q: %v", r.PathValue("month"), err), 400)
+		return
+	}
+	sort, err := parseIssueSort(r.URL.Query().Get("sort"))
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+	opts, err := reportOptionsFromRequest(r)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+	report, err := buildReport(opts)
 	if err != nil {
 		http.Error(w, err.Error(), 500)
 		return
 	}
 
-	err = serveSpecificHTMLReport(w, report)
+	name := r.PathValue("name")
+	data := report.drillDown(ym, name, sort)
+	if data == nil {
+		http.Error(w, fmt.Sprintf("nothing scheduled for %s in %v", cmp.Or(name, "the report"), ym), 404)
+		return
+	}
+	title := data.Month.Name
+	if data.Initiative != nil {
+		title = data.Initiative.Name + ", " + title
+	}
+
+	err = renderPage(w, r, title, monthTmpl, data)
 	if err != nil {
 		http.Error(w, err.Error(), 500)
 		return
 	}
 }
 
-func serveSpecificHTMLReport(w http.ResponseWriter, report *Report) error {
-	// Check if there are any orphans
-	hasOrphans := false
-	for _, md := range report.Months {
-		if other, ok := md.Initiatives["Other"]; ok && len(other.Issues) > 0 {
-			hasOrphans = true
-			break
+func serveTrends(w http.ResponseWriter, r *http.Request) {
+	snapshots, err := loadSnapshots(config.SnapshotsDir)
+	if err != nil {
+		http.Error(w, err.Error(), 500)
+		return
+	}
+
+	data := TrendsPageData{Enabled: config.SnapshotsDir != ""}
+	for _, ym := range trendMonths(snapshots, time.Now()) {
+		chart, err := chartHTML(burnUpChart(snapshots, ym))
+		if err != nil {
+			http.Error(w, err.Error(), 500)
+			return
 		}
+		data.Trends = append(data.Trends, &Trend{
+			Month:  ym,
+			Name:   ym.Time().Format("January 2006"),
+			Latest: snapshots[len(snapshots)-1].Month(ym),
+			Chart:  chart,
+		})
 	}
 
-	// Render the report template
-	var content strings.Builder
-	err := reportTmpl.Execute(&content, ReportPageData{
-		Report:     report,
-		HasOrphans: hasOrphans,
-	})
+	err = renderPage(w, r, "Trends", trendsTmpl, data)
 	if err != nil {
-		return err
+		http.Error(w, err.Error(), 500)
+		return
 	}
+}
 
-	// Render the layout template
+// serveTrendChart serves the burn-up chart of a month as /trends/2025-07.svg.
+func serveTrendChart(w http.ResponseWriter, r *http.Request) {
+	name, ok := strings.CutSuffix(r.PathValue("file"), ".svg")
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	ym, err := yearmonth.Parse(name)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+	snapshots, err := loadSnapshots(config.SnapshotsDir)
+	if err != nil {
+		http.Error(w, err.Error(), 500)
+		return
+	}
+
+	w.Header().Set("Content-Type", "image/svg+xml")
+	err = writeBarChart(w, burnUpChart(snapshots, ym))
+	if err != nil {
+		log.Printf("rendering trend of %v: %v", ym, err)
+	}
+}
+
+func renderHTMLReport(w io.Writer, report *Report, opts RenderOptions) error {
+	if opts.Grouping != nil {
+		return writePage(w, "Linear Report", groupsTmpl, GroupedPageData{
+			Grouping: opts.Grouping,
+			Root:     groupReport(report, opts.Grouping),
+		}, opts.Standalone)
+	}
+	return writePage(w, "Linear Report", reportTmpl, ReportPageData{
+		Report:     report,
+		Dimensions: config.Dimensions,
+	}, opts.Standalone)
+}
+
+func renderJSONReport(w io.Writer, report *Report, opts RenderOptions) error {
+	grouping := opts.Grouping
+	if grouping == nil {
+		grouping = defaultGrouping
+	}
+	return json.NewEncoder(w).Encode(groupReport(report, grouping))
+}
+
+// renderPage renders a content template wrapped into the layout.
+func renderPage(w http.ResponseWriter, r *http.Request, title string, tmpl *template.Template, data any) error {
 	w.Header().Set("Content-Type", "text/html; charset=utf-8")
-	err = layoutTmpl.Execute(w, PageData{
-		Title:   "Linear Report",
-		Content: template.HTML(content.String()),
-	})
+	return writePage(w, title, tmpl, data, isStandalone(r))
+}
+
+// writePage renders a content template wrapped into the layout. Standalone
+// pages inline their styles instead of linking to /static/.
+func writePage(w io.Writer, title string, tmpl *template.Template, data any, standalone bool) error {
+	// Render the content template
+	var content strings.Builder
+	err := tmpl.Execute(&content, data)
 	if err != nil {
 		return err
 	}
 
-	return nil
+	// Render the layout template
+	page := PageData{
+		Title:   title,
+		Content: template.HTML(content.String()),
+	}
+	if standalone {
+		page.Styles = inlineStyles()
+	} else {
+		page.StylesURL = staticURL("app.css")
+	}
+	return layoutTmpl.Execute(w, page)
 }
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"encoding/json"
 	"net/http/httptest"
 	"strings"
 	"testing"
@@ -65,7 +66,7 @@ func TestServeSpecificHTMLReport(t *testing.T) {
 	w := httptest.NewRecorder()
 
 	// Call the handler
-	err := serveSpecificHTMLReport(w, report)
+	err := writeReport(w, lookupFormat("html"), report, RenderOptions{})
 	if err != nil {
 		t.Fatalf("Failed to serve HTML report: %v", err)
 	}
@@ -95,3 +96,91 @@ func TestServeSpecificHTMLReport(t *testing.T) {
 		t.Errorf("Wrong content type, got %q, want text/html; charset=utf-8", contentType)
 	}
 }
+
+func TestClientsPage(t *testing.T) {
+	report := newMockReport()
+	month := report.Months[0]
+	month.SortedClients = []*ClientData{
+		{Name: "Acme", Fixed: 30, Used: 30, Budget: 20, Issues: month.SortedInitiatives[0].Issues},
+		{Name: "Globex", Used: 0, Budget: 10},
+	}
+
+	w := httptest.NewRecorder()
+	err := renderPage(w, httptest.NewRequest("GET", "/", nil), "Clients", clientsTmpl, ReportPageData{Report: report})
+	if err != nil {
+		t.Fatalf("Failed to render clients page: %v", err)
+	}
+
+	response := w.Body.String()
+	for _, s := range []string{"February 2025", "Acme", "Globex", "DEV-123", "-10"} {
+		if !strings.Contains(response, s) {
+			t.Errorf("Response missing expected string: %q", s)
+		}
+	}
+}
+
+func TestExplainPage(t *testing.T) {
+	report := newMockReport()
+	issue := report.Months[0].SortedInitiatives[0].Issues[0]
+	issue.MonthName, issue.InitName = "February 2025", "AG MVP"
+	report.Conflicts = []*DependencyConflict{
+		{Kind: ConflictFixedBlocked, Issues: []string{"DEV-123", "DEV-9"}, Description: "Fixed DEV-123 is blocked by unscheduled DEV-9"},
+	}
+
+	if report.Explain("DEV-999") != nil {
+		t.Errorf("Explain() of an unknown issue should return nil")
+	}
+	e := report.Explain("DEV-123")
+	if e == nil {
+		t.Fatal("Explain() returned nil")
+	}
+
+	w := httptest.NewRecorder()
+	if err := renderPage(w, httptest.NewRequest("GET", "/", nil), e.Identifier, explainTmpl, e); err != nil {
+		t.Fatalf("Failed to render explanation: %v", err)
+	}
+	response := w.Body.String()
+	for _, expected := range []string{"Implement feature X", "AG MVP", "February 2025", "blocked by unscheduled DEV-9"} {
+		if !strings.Contains(response, expected) {
+			t.Errorf("Expected response to contain %q", expected)
+		}
+	}
+}
+
+func TestJSONReport(t *testing.T) {
+	var sb strings.Builder
+	if err := renderJSONReport(&sb, newMockReport(), RenderOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	var root struct {
+		Children []struct {
+			Children []struct {
+				Issues []map[string]any `json:"issues"`
+			} `json:"children"`
+		} `json:"children"`
+	}
+	if err := json.Unmarshal([]byte(sb.String()), &root); err != nil {
+		t.Fatal(err)
+	}
+	issue := root.Children[0].Children[0].Issues[0]
+	if issue["identifier"] != "DEV-123" || issue["points"] != 5.0 || issue["schedule"] != "Fixed" {
+		t.Errorf("issue = %v", issue)
+	}
+}
+
+func TestServeReportBadGrouping(t *testing.T) {
+	// Fails before fetching issues, which would need a Linear API key
+	w := httptest.NewRecorder()
+	serveReport(w, httptest.NewRequest("GET", "/report.json?group=month,nope", nil))
+	if w.Code != 400 || !strings.Contains(w.Body.String(), "nope") {
+		t.Errorf("status = %d, body = %q, want 400", w.Code, w.Body.String())
+	}
+}
+
+func TestServeReportBadChart(t *testing.T) {
+	w := httptest.NewRecorder()
+	serveReport(w, httptest.NewRequest("GET", "/report.svg?chart=pie", nil))
+	if w.Code != 400 || w.Header().Get("Content-Type") == "image/svg+xml" {
+		t.Errorf("status = %d, Content-Type = %q, want a 400 error", w.Code, w.Header().Get("Content-Type"))
+	}
+}
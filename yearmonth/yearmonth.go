@@ -70,3 +70,24 @@ func (ym *YM) UnmarshalText(data []byte) error {
 	*ym = v
 	return nil
 }
+
+// Next returns the month after ym.
+func (ym YM) Next() YM {
+	year, month := ym.Components()
+	if month == 12 {
+		return Make(year+1, 1)
+	}
+	return Make(year, month+1)
+}
+
+// Quarter returns the quarter of the year (1 to 4) that ym belongs to.
+func (ym YM) Quarter() int {
+	_, month := ym.Components()
+	return (month-1)/3 + 1
+}
+
+// Time returns midnight UTC of the first day of the month.
+func (ym YM) Time() time.Time {
+	year, month := ym.Components()
+	return time.Date(year, time.Month(month), 1, 0, 0, 0, 0, time.UTC)
+}